@@ -24,6 +24,9 @@ type Expression interface {
 
 type Program struct {
 	Statements []Statement
+	// Comments holds every comment of the source, in order. They are not
+	// attached to any statement.
+	Comments []*Comment
 }
 
 func (p *Program) TokenLiteral() string {
@@ -42,9 +45,19 @@ func (p *Program) String() string {
 	return out.String()
 }
 
+// Comment is a line comment, whose text includes the leading '//'.
+type Comment struct {
+	Token token.Token
+}
+
+func (c *Comment) TokenLiteral() string { return c.Token.Literal }
+func (c *Comment) String() string       { return c.Token.Literal }
+
 type LetStatement struct {
 	Token token.Token
 	Name  *Identifier
+	// Type is the annotated type of the binding, or nil if there is none.
+	Type  TypeExpr
 	Value Expression
 }
 
@@ -55,6 +68,9 @@ func (ls *LetStatement) String() string {
 
 	out.WriteString(ls.TokenLiteral() + " ")
 	out.WriteString(ls.Name.String())
+	if ls.Type != nil {
+		out.WriteString(": " + ls.Type.String())
+	}
 	out.WriteString(" = ")
 
 	if ls.Value != nil {
@@ -85,6 +101,19 @@ func (rs *ReturnStatement) String() string {
 	return out.String()
 }
 
+// ExportStatement makes the binding of a top-level let statement available
+// to the programs importing the module it appears in.
+type ExportStatement struct {
+	Token     token.Token
+	Statement *LetStatement
+}
+
+func (es *ExportStatement) statementNode()       {}
+func (es *ExportStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *ExportStatement) String() string {
+	return es.TokenLiteral() + " " + es.Statement.String()
+}
+
 type ExpressionStatement struct {
 	Token      token.Token
 	Expression Expression
@@ -99,9 +128,29 @@ func (es *ExpressionStatement) String() string {
 	return ""
 }
 
+// Scope tells where the variable named by an identifier lives, as found by
+// the resolver ahead of evaluation.
+type Scope int
+
+const (
+	// Unresolved identifiers are looked up by name, from the innermost
+	// environment outwards.
+	Unresolved Scope = iota
+	// GlobalScope identifiers are looked up by name among the bindings of
+	// the top level, which may be defined after the code using them.
+	GlobalScope
+	// LocalScope identifiers are in slot Index of the function Depth levels
+	// out from where they appear.
+	LocalScope
+)
+
 type Identifier struct {
 	Token token.Token
 	Value string
+
+	Scope Scope
+	Depth int
+	Index int
 }
 
 func (i *Identifier) expressionNode()      {}
@@ -193,6 +242,8 @@ func (ifx *IfExpression) String() string {
 type BlockStatement struct {
 	Token      token.Token
 	Statements []Statement
+	// Rbrace is the closing brace of the block.
+	Rbrace token.Token
 }
 
 func (bs *BlockStatement) statementNode()       {}
@@ -209,7 +260,16 @@ func (bs *BlockStatement) String() string {
 type FunctionLiteral struct {
 	Token      token.Token
 	Parameters []*Identifier
+	// ParameterTypes holds the annotated type of each parameter, nil for
+	// those without one. It is nil when no parameter is annotated.
+	ParameterTypes []TypeExpr
+	// ReturnType is the annotated type of the result, or nil if there is
+	// none.
+	ReturnType TypeExpr
 	Body       *BlockStatement
+	// Locals names the slots of the function's variables, starting with
+	// its parameters. It is nil until the function is resolved.
+	Locals []string
 }
 
 func (fl *FunctionLiteral) expressionNode()      {}
@@ -218,22 +278,67 @@ func (fl *FunctionLiteral) String() string {
 	var out bytes.Buffer
 
 	params := []string{}
-	for _, p := range fl.Parameters {
-		params = append(params, p.String())
+	for i, p := range fl.Parameters {
+		if t := fl.ParameterType(i); t != nil {
+			params = append(params, p.String()+": "+t.String())
+		} else {
+			params = append(params, p.String())
+		}
 	}
 	out.WriteString(fl.TokenLiteral())
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ", "))
 	out.WriteString(") ")
+	if fl.ReturnType != nil {
+		out.WriteString("-> " + fl.ReturnType.String() + " ")
+	}
 	out.WriteString(fl.Body.String())
 
 	return out.String()
 }
 
+// ParameterType returns the annotated type of parameter i, or nil if it has
+// none.
+func (fl *FunctionLiteral) ParameterType(i int) TypeExpr {
+	if i < len(fl.ParameterTypes) {
+		return fl.ParameterTypes[i]
+	}
+	return nil
+}
+
+// MacroLiteral defines a macro, whose body runs before the program does,
+// receiving its arguments as quoted code and returning the code to put in
+// place of its call.
+type MacroLiteral struct {
+	Token      token.Token
+	Parameters []*Identifier
+	Body       *BlockStatement
+}
+
+func (ml *MacroLiteral) expressionNode()      {}
+func (ml *MacroLiteral) TokenLiteral() string { return ml.Token.Literal }
+func (ml *MacroLiteral) String() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range ml.Parameters {
+		params = append(params, p.String())
+	}
+	out.WriteString(ml.TokenLiteral())
+	out.WriteString("(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") ")
+	out.WriteString(ml.Body.String())
+
+	return out.String()
+}
+
 type CallExpression struct {
 	Token     token.Token
 	Function  Expression
 	Arguments []Expression
+	// Rparen is the closing parenthesis of the arguments.
+	Rparen token.Token
 }
 
 func (ce *CallExpression) expressionNode()      {}
@@ -265,6 +370,8 @@ func (sl *StringLiteral) String() string       { return sl.Token.Literal }
 type ArrayLiteral struct {
 	Token    token.Token
 	Elements []Expression
+	// Rbracket is the closing bracket of the array.
+	Rbracket token.Token
 }
 
 func (al *ArrayLiteral) expressionNode()      {}
@@ -287,6 +394,8 @@ type IndexExpression struct {
 	Token token.Token
 	Left  Expression
 	Index Expression
+	// Rbracket is the closing bracket of the index.
+	Rbracket token.Token
 }
 
 func (ie *IndexExpression) expressionNode()      {}
@@ -306,6 +415,10 @@ func (ie *IndexExpression) String() string {
 type HashLiteral struct {
 	Token token.Token
 	Pairs map[Expression]Expression
+	// Keys holds the keys of Pairs in the order they appear in the source.
+	Keys []Expression
+	// Rbrace is the closing brace of the hash.
+	Rbrace token.Token
 }
 
 func (hl *HashLiteral) expressionNode()      {}
@@ -314,8 +427,8 @@ func (hl *HashLiteral) String() string {
 	var out bytes.Buffer
 
 	pairs := []string{}
-	for k, v := range hl.Pairs {
-		pairs = append(pairs, k.String()+":"+v.String())
+	for _, k := range hl.Keys {
+		pairs = append(pairs, k.String()+":"+hl.Pairs[k].String())
 	}
 	out.WriteString("{")
 	out.WriteString(strings.Join(pairs, ", "))
@@ -323,3 +436,73 @@ func (hl *HashLiteral) String() string {
 
 	return out.String()
 }
+
+type ImportExpression struct {
+	Token token.Token
+	Path  *StringLiteral
+}
+
+func (ie *ImportExpression) expressionNode()      {}
+func (ie *ImportExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *ImportExpression) String() string {
+	return ie.TokenLiteral() + ` "` + ie.Path.Value + `"`
+}
+
+// TypeExpr is a type annotation. Annotations are only read by the type
+// checker: evaluation ignores them, and Walk, Inspect and Modify do not visit
+// them.
+type TypeExpr interface {
+	Node
+	typeNode()
+}
+
+// NamedType is a type referred to by name, such as int or string.
+type NamedType struct {
+	Token token.Token
+	Name  string
+}
+
+func (nt *NamedType) typeNode()            {}
+func (nt *NamedType) TokenLiteral() string { return nt.Token.Literal }
+func (nt *NamedType) String() string       { return nt.Name }
+
+// ArrayType is the type of arrays whose elements are of type Element,
+// written [Element].
+type ArrayType struct {
+	Token   token.Token
+	Element TypeExpr
+}
+
+func (at *ArrayType) typeNode()            {}
+func (at *ArrayType) TokenLiteral() string { return at.Token.Literal }
+func (at *ArrayType) String() string       { return "[" + at.Element.String() + "]" }
+
+// HashType is the type of hashes from Key to Value, written {Key: Value}.
+type HashType struct {
+	Token token.Token
+	Key   TypeExpr
+	Value TypeExpr
+}
+
+func (ht *HashType) typeNode()            {}
+func (ht *HashType) TokenLiteral() string { return ht.Token.Literal }
+func (ht *HashType) String() string {
+	return "{" + ht.Key.String() + ": " + ht.Value.String() + "}"
+}
+
+// FunctionType is the type of functions, written fn(Parameters) -> Return.
+type FunctionType struct {
+	Token      token.Token
+	Parameters []TypeExpr
+	Return     TypeExpr
+}
+
+func (ft *FunctionType) typeNode()            {}
+func (ft *FunctionType) TokenLiteral() string { return ft.Token.Literal }
+func (ft *FunctionType) String() string {
+	params := []string{}
+	for _, p := range ft.Parameters {
+		params = append(params, p.String())
+	}
+	return "fn(" + strings.Join(params, ", ") + ") -> " + ft.Return.String()
+}
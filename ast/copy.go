@@ -0,0 +1,165 @@
+package ast
+
+// Copy returns a deep copy of the tree rooted at node, so that it can be
+// modified without affecting the original. Tokens are copied as they are,
+// and type annotations, which nothing modifies, are shared.
+func Copy(node Node) Node {
+	switch n := node.(type) {
+	case *Program:
+		c := *n
+		c.Statements = copyStatements(n.Statements)
+		return &c
+	case *LetStatement:
+		c := *n
+		c.Name = copyIdentifier(n.Name)
+		c.Value = copyExpression(n.Value)
+		return &c
+	case *ReturnStatement:
+		c := *n
+		c.ReturnValue = copyExpression(n.ReturnValue)
+		return &c
+	case *ExportStatement:
+		c := *n
+		c.Statement, _ = Copy(n.Statement).(*LetStatement)
+		return &c
+	case *ExpressionStatement:
+		c := *n
+		c.Expression = copyExpression(n.Expression)
+		return &c
+	case *BlockStatement:
+		c := *n
+		c.Statements = copyStatements(n.Statements)
+		return &c
+	case *Identifier:
+		c := *n
+		return &c
+	case *IntegerLiteral:
+		c := *n
+		return &c
+	case *Boolean:
+		c := *n
+		return &c
+	case *StringLiteral:
+		c := *n
+		return &c
+	case *PrefixExpression:
+		c := *n
+		c.Right = copyExpression(n.Right)
+		return &c
+	case *InfixExpression:
+		c := *n
+		c.Left = copyExpression(n.Left)
+		c.Right = copyExpression(n.Right)
+		return &c
+	case *IfExpression:
+		c := *n
+		c.Condition = copyExpression(n.Condition)
+		c.Consequence = copyBlock(n.Consequence)
+		c.Alternative = copyBlock(n.Alternative)
+		return &c
+	case *FunctionLiteral:
+		c := *n
+		c.Parameters = copyIdentifiers(n.Parameters)
+		c.Body = copyBlock(n.Body)
+		return &c
+	case *MacroLiteral:
+		c := *n
+		c.Parameters = copyIdentifiers(n.Parameters)
+		c.Body = copyBlock(n.Body)
+		return &c
+	case *CallExpression:
+		c := *n
+		c.Function = copyExpression(n.Function)
+		c.Arguments = copyExpressions(n.Arguments)
+		return &c
+	case *ArrayLiteral:
+		c := *n
+		c.Elements = copyExpressions(n.Elements)
+		return &c
+	case *IndexExpression:
+		c := *n
+		c.Left = copyExpression(n.Left)
+		c.Index = copyExpression(n.Index)
+		return &c
+	case *HashLiteral:
+		c := *n
+		c.Keys = make([]Expression, len(n.Keys))
+		c.Pairs = make(map[Expression]Expression, len(n.Pairs))
+		for i, key := range n.Keys {
+			c.Keys[i] = copyExpression(key)
+			c.Pairs[c.Keys[i]] = copyExpression(n.Pairs[key])
+		}
+		return &c
+	case *ImportExpression:
+		c := *n
+		if n.Path != nil {
+			path := *n.Path
+			c.Path = &path
+		}
+		return &c
+	case *Comment:
+		c := *n
+		return &c
+	default:
+		return node
+	}
+}
+
+func copyStatements(stmts []Statement) []Statement {
+	if stmts == nil {
+		return nil
+	}
+	result := make([]Statement, len(stmts))
+	for i, stmt := range stmts {
+		if stmt != nil {
+			result[i], _ = Copy(stmt).(Statement)
+		}
+	}
+	return result
+}
+
+func copyExpression(e Expression) Expression {
+	if e == nil {
+		return nil
+	}
+	result, _ := Copy(e).(Expression)
+
+	return result
+}
+
+func copyExpressions(exprs []Expression) []Expression {
+	if exprs == nil {
+		return nil
+	}
+	result := make([]Expression, len(exprs))
+	for i, e := range exprs {
+		result[i] = copyExpression(e)
+	}
+	return result
+}
+
+func copyIdentifier(id *Identifier) *Identifier {
+	if id == nil {
+		return nil
+	}
+	c := *id
+	return &c
+}
+
+func copyIdentifiers(ids []*Identifier) []*Identifier {
+	if ids == nil {
+		return nil
+	}
+	result := make([]*Identifier, len(ids))
+	for i, id := range ids {
+		result[i] = copyIdentifier(id)
+	}
+	return result
+}
+
+func copyBlock(b *BlockStatement) *BlockStatement {
+	if b == nil {
+		return nil
+	}
+	return Copy(b).(*BlockStatement)
+}
@@ -0,0 +1,50 @@
+package ast
+
+import (
+	"testing"
+
+	"monkey/token"
+)
+
+func TestCopy(t *testing.T) {
+	original := &Program{Statements: []Statement{
+		&LetStatement{
+			Token: token.Token{Type: token.LET, Literal: "let"},
+			Name:  ident("f"),
+			Value: &FunctionLiteral{
+				Token:      token.Token{Type: token.FUNCTION, Literal: "fn"},
+				Parameters: []*Identifier{ident("x")},
+				Body: &BlockStatement{Statements: []Statement{
+					&ExpressionStatement{Expression: &ArrayLiteral{
+						Elements: []Expression{ident("x"), hash(ident("x"), ident("x"))},
+					}},
+				}},
+			},
+		},
+		&ExpressionStatement{Expression: &IfExpression{
+			Condition:   ident("x"),
+			Consequence: &BlockStatement{},
+		}},
+	}}
+	expected := original.String()
+
+	copied := Copy(original)
+	if copied.String() != expected {
+		t.Fatalf("copy differs. want=%q, got=%q", expected, copied.String())
+	}
+
+	Modify(copied, func(node Node) Node {
+		if id, ok := node.(*Identifier); ok && id.Value == "x" {
+			id.Value = "y"
+			id.Token.Literal = "y"
+		}
+		return node
+	})
+
+	if original.String() != expected {
+		t.Errorf("modifying the copy changed the original. got=%q", original.String())
+	}
+	if copied.String() == expected {
+		t.Errorf("copy was not modified. got=%q", copied.String())
+	}
+}
@@ -0,0 +1,65 @@
+package ast
+
+import "monkey/token"
+
+// StartToken returns the token node starts at in the source, or an empty
+// token for nodes it cannot tell, such as those built without positions.
+// Operators, calls and index expressions start at their left-hand side
+// rather than at their own token.
+func StartToken(node Node) token.Token {
+	switch n := node.(type) {
+	case *Program:
+		if len(n.Statements) > 0 {
+			return StartToken(n.Statements[0])
+		}
+	case *Comment:
+		return n.Token
+	case *LetStatement:
+		return n.Token
+	case *ReturnStatement:
+		return n.Token
+	case *ExportStatement:
+		return n.Token
+	case *ExpressionStatement:
+		return n.Token
+	case *BlockStatement:
+		return n.Token
+	case *Identifier:
+		return n.Token
+	case *IntegerLiteral:
+		return n.Token
+	case *StringLiteral:
+		return n.Token
+	case *Boolean:
+		return n.Token
+	case *PrefixExpression:
+		return n.Token
+	case *InfixExpression:
+		return StartToken(n.Left)
+	case *IfExpression:
+		return n.Token
+	case *FunctionLiteral:
+		return n.Token
+	case *MacroLiteral:
+		return n.Token
+	case *CallExpression:
+		return StartToken(n.Function)
+	case *ArrayLiteral:
+		return n.Token
+	case *IndexExpression:
+		return StartToken(n.Left)
+	case *HashLiteral:
+		return n.Token
+	case *ImportExpression:
+		return n.Token
+	case *NamedType:
+		return n.Token
+	case *ArrayType:
+		return n.Token
+	case *HashType:
+		return n.Token
+	case *FunctionType:
+		return n.Token
+	}
+	return token.Token{}
+}
@@ -0,0 +1,37 @@
+package ast
+
+import (
+	"testing"
+
+	"monkey/token"
+)
+
+func TestStartToken(t *testing.T) {
+	at := func(line, column int) token.Token {
+		return token.Token{Line: line, Column: column}
+	}
+	a := &Identifier{Token: at(1, 1), Value: "a"}
+	b := &Identifier{Token: at(1, 5), Value: "b"}
+
+	for _, tt := range []struct {
+		node     Node
+		expected token.Token
+	}{
+		{a, at(1, 1)},
+		{&LetStatement{Token: at(2, 1), Name: a, Value: b}, at(2, 1)},
+		{&ExportStatement{Token: at(3, 1), Statement: &LetStatement{Token: at(3, 8)}}, at(3, 1)},
+		{&ExpressionStatement{Token: at(4, 1), Expression: b}, at(4, 1)},
+		{&InfixExpression{Token: at(1, 3), Left: a, Operator: "+", Right: b}, at(1, 1)},
+		{&CallExpression{Token: at(1, 2), Function: a, Arguments: []Expression{b}}, at(1, 1)},
+		{&IndexExpression{Token: at(1, 2), Left: &CallExpression{Token: at(1, 4), Function: a}, Index: b}, at(1, 1)},
+		{&PrefixExpression{Token: at(5, 2), Operator: "-", Right: a}, at(5, 2)},
+		{&FunctionType{Token: at(6, 8)}, at(6, 8)},
+		{&Program{Statements: []Statement{&ReturnStatement{Token: at(7, 3)}}}, at(7, 3)},
+		{&Program{}, token.Token{}},
+		{&InfixExpression{Token: at(1, 3), Operator: "+", Right: b}, token.Token{}},
+	} {
+		if got := StartToken(tt.node); got != tt.expected {
+			t.Errorf("wrong start of %T. expected %+v, got %+v", tt.node, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package ast
+
+// Visitor's Visit method is called by Walk for every node. If it returns a
+// visitor w, the children of the node are walked with w, and then w.Visit is
+// called with nil.
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses the tree rooted at node in depth-first order, visiting the
+// children of a node in the order they appear in the source. Comments of a
+// program are not visited.
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		walkStatements(v, n.Statements)
+	case *LetStatement:
+		Walk(v, n.Name)
+		walkExpression(v, n.Value)
+	case *ReturnStatement:
+		walkExpression(v, n.ReturnValue)
+	case *ExportStatement:
+		Walk(v, n.Statement)
+	case *ExpressionStatement:
+		walkExpression(v, n.Expression)
+	case *BlockStatement:
+		walkStatements(v, n.Statements)
+	case *PrefixExpression:
+		walkExpression(v, n.Right)
+	case *InfixExpression:
+		walkExpression(v, n.Left)
+		walkExpression(v, n.Right)
+	case *IfExpression:
+		walkExpression(v, n.Condition)
+		Walk(v, n.Consequence)
+		if n.Alternative != nil {
+			Walk(v, n.Alternative)
+		}
+	case *FunctionLiteral:
+		for _, param := range n.Parameters {
+			Walk(v, param)
+		}
+		Walk(v, n.Body)
+	case *MacroLiteral:
+		for _, param := range n.Parameters {
+			Walk(v, param)
+		}
+		Walk(v, n.Body)
+	case *CallExpression:
+		walkExpression(v, n.Function)
+		walkExpressions(v, n.Arguments)
+	case *ArrayLiteral:
+		walkExpressions(v, n.Elements)
+	case *IndexExpression:
+		walkExpression(v, n.Left)
+		walkExpression(v, n.Index)
+	case *HashLiteral:
+		for _, key := range n.Keys {
+			walkExpression(v, key)
+			walkExpression(v, n.Pairs[key])
+		}
+	case *ImportExpression:
+		Walk(v, n.Path)
+	}
+
+	v.Visit(nil)
+}
+
+// walkStatements skips missing statements, which nodes that failed to parse
+// may be left with. The same goes for other walk and modify helpers.
+func walkStatements(v Visitor, stmts []Statement) {
+	for _, stmt := range stmts {
+		if stmt != nil {
+			Walk(v, stmt)
+		}
+	}
+}
+
+func walkExpression(v Visitor, e Expression) {
+	if e != nil {
+		Walk(v, e)
+	}
+}
+
+func walkExpressions(v Visitor, exprs []Expression) {
+	for _, e := range exprs {
+		walkExpression(v, e)
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses the tree rooted at node like Walk, calling f for every
+// node. The children of a node are skipped if f returns false for it, and f
+// is called with nil once they are done.
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
+
+// ModifierFunc returns the node to put in place of the given one.
+type ModifierFunc func(Node) Node
+
+// Modify rewrites the tree rooted at node from the bottom up: the children
+// of a node are replaced with the result of modifying them before modifier
+// is called on the node itself. Nodes are changed in place, and the result
+// of calling modifier on node is returned.
+//
+// A modifier must give back a node that fits where the original one was,
+// such as an expression in place of an expression. Parameters of functions
+// and names of let statements must stay identifiers.
+func Modify(node Node, modifier ModifierFunc) Node {
+	switch n := node.(type) {
+	case *Program:
+		modifyStatements(n.Statements, modifier)
+	case *LetStatement:
+		n.Name, _ = Modify(n.Name, modifier).(*Identifier)
+		n.Value = modifyExpression(n.Value, modifier)
+	case *ReturnStatement:
+		n.ReturnValue = modifyExpression(n.ReturnValue, modifier)
+	case *ExportStatement:
+		n.Statement, _ = Modify(n.Statement, modifier).(*LetStatement)
+	case *ExpressionStatement:
+		n.Expression = modifyExpression(n.Expression, modifier)
+	case *BlockStatement:
+		modifyStatements(n.Statements, modifier)
+	case *PrefixExpression:
+		n.Right = modifyExpression(n.Right, modifier)
+	case *InfixExpression:
+		n.Left = modifyExpression(n.Left, modifier)
+		n.Right = modifyExpression(n.Right, modifier)
+	case *IfExpression:
+		n.Condition = modifyExpression(n.Condition, modifier)
+		n.Consequence, _ = Modify(n.Consequence, modifier).(*BlockStatement)
+		if n.Alternative != nil {
+			n.Alternative, _ = Modify(n.Alternative, modifier).(*BlockStatement)
+		}
+	case *FunctionLiteral:
+		for i, param := range n.Parameters {
+			n.Parameters[i], _ = Modify(param, modifier).(*Identifier)
+		}
+		n.Body, _ = Modify(n.Body, modifier).(*BlockStatement)
+	case *MacroLiteral:
+		for i, param := range n.Parameters {
+			n.Parameters[i], _ = Modify(param, modifier).(*Identifier)
+		}
+		n.Body, _ = Modify(n.Body, modifier).(*BlockStatement)
+	case *CallExpression:
+		n.Function = modifyExpression(n.Function, modifier)
+		modifyExpressions(n.Arguments, modifier)
+	case *ArrayLiteral:
+		modifyExpressions(n.Elements, modifier)
+	case *IndexExpression:
+		n.Left = modifyExpression(n.Left, modifier)
+		n.Index = modifyExpression(n.Index, modifier)
+	case *HashLiteral:
+		pairs := make(map[Expression]Expression, len(n.Pairs))
+		for i, key := range n.Keys {
+			newKey := modifyExpression(key, modifier)
+			pairs[newKey] = modifyExpression(n.Pairs[key], modifier)
+			n.Keys[i] = newKey
+		}
+		n.Pairs = pairs
+	case *ImportExpression:
+		n.Path, _ = Modify(n.Path, modifier).(*StringLiteral)
+	}
+
+	return modifier(node)
+}
+
+func modifyStatements(stmts []Statement, modifier ModifierFunc) {
+	for i, stmt := range stmts {
+		if stmt != nil {
+			stmts[i], _ = Modify(stmt, modifier).(Statement)
+		}
+	}
+}
+
+func modifyExpression(e Expression, modifier ModifierFunc) Expression {
+	if e == nil {
+		return nil
+	}
+	result, _ := Modify(e, modifier).(Expression)
+
+	return result
+}
+
+func modifyExpressions(exprs []Expression, modifier ModifierFunc) {
+	for i, e := range exprs {
+		exprs[i] = modifyExpression(e, modifier)
+	}
+}
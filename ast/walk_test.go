@@ -0,0 +1,212 @@
+package ast
+
+import (
+	"reflect"
+	"testing"
+
+	"monkey/token"
+)
+
+func TestInspect(t *testing.T) {
+	program := &Program{Statements: []Statement{
+		&ExportStatement{Statement: &LetStatement{
+			Name: ident("f"),
+			Value: &FunctionLiteral{
+				Parameters: []*Identifier{ident("a"), ident("b")},
+				Body: &BlockStatement{Statements: []Statement{
+					&ExpressionStatement{Expression: &IfExpression{
+						Condition:   &InfixExpression{Left: ident("a"), Operator: "<", Right: ident("b")},
+						Consequence: &BlockStatement{Statements: []Statement{&ReturnStatement{ReturnValue: ident("c")}}},
+						Alternative: &BlockStatement{Statements: []Statement{
+							&ExpressionStatement{Expression: &PrefixExpression{Operator: "-", Right: ident("d")}},
+						}},
+					}},
+				}},
+			},
+		}},
+		&ExpressionStatement{Expression: &CallExpression{
+			Function: ident("e"),
+			Arguments: []Expression{
+				&ArrayLiteral{Elements: []Expression{ident("g"), &IndexExpression{Left: ident("h"), Index: ident("i")}}},
+				hash(ident("j"), ident("k"), ident("l"), ident("m")),
+				&ImportExpression{Path: &StringLiteral{Value: "n"}},
+			},
+		}},
+	}}
+
+	names := []string{}
+	depth, maxDepth := 0, 0
+	Inspect(program, func(node Node) bool {
+		if node == nil {
+			depth--
+			return false
+		}
+		depth++
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+		switch n := node.(type) {
+		case *Identifier:
+			names = append(names, n.Value)
+		case *StringLiteral:
+			names = append(names, n.Value)
+		}
+		return true
+	})
+
+	expected := []string{"f", "a", "b", "a", "b", "c", "d", "e", "g", "h", "i", "j", "k", "l", "m", "n"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("wrong nodes visited. expected %v, got %v", expected, names)
+	}
+	if depth != 0 {
+		t.Errorf("Visit was not called with nil after each node, depth is %d", depth)
+	}
+	if maxDepth != 11 {
+		t.Errorf("expected a depth of 11, got %d", maxDepth)
+	}
+}
+
+func TestInspectSkipsChildren(t *testing.T) {
+	program := &Program{Statements: []Statement{
+		&ExpressionStatement{Expression: &FunctionLiteral{
+			Parameters: []*Identifier{ident("a")},
+			Body:       &BlockStatement{Statements: []Statement{&ExpressionStatement{Expression: ident("b")}}},
+		}},
+		&ExpressionStatement{Expression: ident("c")},
+	}}
+
+	names := []string{}
+	Inspect(program, func(node Node) bool {
+		if id, ok := node.(*Identifier); ok {
+			names = append(names, id.Value)
+		}
+		_, isFunction := node.(*FunctionLiteral)
+		return !isFunction
+	})
+
+	if !reflect.DeepEqual(names, []string{"c"}) {
+		t.Errorf("expected only c to be visited, got %v", names)
+	}
+}
+
+func TestModify(t *testing.T) {
+	one := func() Expression { return &IntegerLiteral{Value: 1} }
+	two := func() Expression { return &IntegerLiteral{Value: 2} }
+
+	turnOneIntoTwo := func(node Node) Node {
+		integer, ok := node.(*IntegerLiteral)
+		if !ok || integer.Value != 1 {
+			return node
+		}
+		integer.Value = 2
+		return integer
+	}
+
+	for _, tt := range []struct {
+		input    Node
+		expected Node
+	}{
+		{one(), two()},
+		{
+			&Program{Statements: []Statement{&ExpressionStatement{Expression: one()}}},
+			&Program{Statements: []Statement{&ExpressionStatement{Expression: two()}}},
+		},
+		{
+			&InfixExpression{Left: one(), Operator: "+", Right: two()},
+			&InfixExpression{Left: two(), Operator: "+", Right: two()},
+		},
+		{
+			&InfixExpression{Left: two(), Operator: "+", Right: one()},
+			&InfixExpression{Left: two(), Operator: "+", Right: two()},
+		},
+		{&PrefixExpression{Operator: "-", Right: one()}, &PrefixExpression{Operator: "-", Right: two()}},
+		{&IndexExpression{Left: one(), Index: one()}, &IndexExpression{Left: two(), Index: two()}},
+		{
+			&IfExpression{
+				Condition:   one(),
+				Consequence: &BlockStatement{Statements: []Statement{&ExpressionStatement{Expression: one()}}},
+				Alternative: &BlockStatement{Statements: []Statement{&ExpressionStatement{Expression: one()}}},
+			},
+			&IfExpression{
+				Condition:   two(),
+				Consequence: &BlockStatement{Statements: []Statement{&ExpressionStatement{Expression: two()}}},
+				Alternative: &BlockStatement{Statements: []Statement{&ExpressionStatement{Expression: two()}}},
+			},
+		},
+		{&ReturnStatement{ReturnValue: one()}, &ReturnStatement{ReturnValue: two()}},
+		{
+			&ExportStatement{Statement: &LetStatement{Name: ident("x"), Value: one()}},
+			&ExportStatement{Statement: &LetStatement{Name: ident("x"), Value: two()}},
+		},
+		{
+			&FunctionLiteral{
+				Parameters: []*Identifier{ident("x")},
+				Body:       &BlockStatement{Statements: []Statement{&ExpressionStatement{Expression: one()}}},
+			},
+			&FunctionLiteral{
+				Parameters: []*Identifier{ident("x")},
+				Body:       &BlockStatement{Statements: []Statement{&ExpressionStatement{Expression: two()}}},
+			},
+		},
+		{
+			&CallExpression{Function: one(), Arguments: []Expression{one(), one()}},
+			&CallExpression{Function: two(), Arguments: []Expression{two(), two()}},
+		},
+		{&ArrayLiteral{Elements: []Expression{one(), one()}}, &ArrayLiteral{Elements: []Expression{two(), two()}}},
+	} {
+		modified := Modify(tt.input, turnOneIntoTwo)
+
+		if !reflect.DeepEqual(modified, tt.expected) {
+			t.Errorf("not equal. got=%#v, want=%#v", modified, tt.expected)
+		}
+	}
+
+	hashLiteral := hash(one(), one(), one(), one())
+	Modify(hashLiteral, turnOneIntoTwo)
+
+	for _, key := range hashLiteral.Keys {
+		if key.(*IntegerLiteral).Value != 2 {
+			t.Errorf("key is not 2, got %d", key.(*IntegerLiteral).Value)
+		}
+		if value := hashLiteral.Pairs[key].(*IntegerLiteral).Value; value != 2 {
+			t.Errorf("value is not 2, got %d", value)
+		}
+	}
+}
+
+func TestModifyReplacesNodes(t *testing.T) {
+	// Rename every use of x, including parameters, to y.
+	rename := func(node Node) Node {
+		if id, ok := node.(*Identifier); ok && id.Value == "x" {
+			return ident("y")
+		}
+		return node
+	}
+	fn := &FunctionLiteral{
+		Token:      token.Token{Type: token.FUNCTION, Literal: "fn"},
+		Parameters: []*Identifier{ident("x")},
+		Body: &BlockStatement{Statements: []Statement{
+			&ExpressionStatement{Expression: &InfixExpression{Left: ident("x"), Operator: "+", Right: ident("z")}},
+		}},
+	}
+
+	Modify(fn, rename)
+
+	if fn.String() != "fn(y) (y + z)" {
+		t.Errorf("wrong result. got=%q", fn.String())
+	}
+}
+
+func ident(name string) *Identifier {
+	return &Identifier{Token: token.Token{Type: token.IDENT, Literal: name}, Value: name}
+}
+
+// hash builds a hash literal out of alternating keys and values.
+func hash(pairs ...Expression) *HashLiteral {
+	h := &HashLiteral{Pairs: make(map[Expression]Expression)}
+	for i := 0; i < len(pairs); i += 2 {
+		h.Pairs[pairs[i]] = pairs[i+1]
+		h.Keys = append(h.Keys, pairs[i])
+	}
+	return h
+}
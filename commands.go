@@ -0,0 +1,375 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"monkey/ast"
+	"monkey/eval"
+	"monkey/format"
+	"monkey/lexer"
+	"monkey/lint"
+	"monkey/object"
+	"monkey/optimize"
+	"monkey/parser"
+	"monkey/token"
+	"monkey/types"
+)
+
+func runCmd(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	check := flags.Bool("check", false, "report type errors, and run nothing if there are any")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(stderr, "usage: monkey run [-check] <file>")
+		return 2
+	}
+	name := flags.Arg(0)
+
+	src, err := readSource(name)
+	if err != nil {
+		fmt.Fprintf(stderr, "monkey: %s\n", err)
+		return 1
+	}
+	program, ok := parse(name, src, stderr)
+	if !ok || *check && !typeCheck(name, program, stderr) {
+		return 1
+	}
+	modules := os.DirFS(filepath.Dir(name))
+
+	return evaluate(name, program, modules, false, stdout, stderr)
+}
+
+func evalCmd(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("eval", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	expr := flags.String("e", "", "expression to evaluate")
+	check := flags.Bool("check", false, "report type errors, and run nothing if there are any")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *expr == "" || flags.NArg() != 0 {
+		fmt.Fprintln(stderr, "usage: monkey eval [-check] -e <expr>")
+		return 2
+	}
+
+	program, ok := parse("-e", *expr, stderr)
+	if !ok || *check && !typeCheck("-e", program, stderr) {
+		return 1
+	}
+	return evaluate("-e", program, os.DirFS("."), true, stdout, stderr)
+}
+
+func tokensCmd(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 1 {
+		fmt.Fprintln(stderr, "usage: monkey tokens <file>")
+		return 2
+	}
+
+	src, err := readSource(args[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "monkey: %s\n", err)
+		return 1
+	}
+
+	l := lexer.New(src)
+	for {
+		tok := l.NextToken()
+		fmt.Fprintf(stdout, "%d:%d\t%s\t%q\n",
+			tok.Line, tok.Column, tok.Type, tok.Literal)
+
+		if tok.Type == token.EOF {
+			return 0
+		}
+	}
+}
+
+func astCmd(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 1 {
+		fmt.Fprintln(stderr, "usage: monkey ast <file>")
+		return 2
+	}
+	name := args[0]
+
+	src, err := readSource(name)
+	if err != nil {
+		fmt.Fprintf(stderr, "monkey: %s\n", err)
+		return 1
+	}
+	program, ok := parse(name, src, stderr)
+	if !ok {
+		return 1
+	}
+	for _, stmt := range program.Statements {
+		fmt.Fprintln(stdout, stmt.String())
+	}
+	return 0
+}
+
+func fmtCmd(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	list := flags.Bool("l", false, "list files whose formatting differs")
+	write := flags.Bool("w", false, "write the result to the files")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		if *write {
+			fmt.Fprintln(stderr, "monkey: cannot use -w with the standard input")
+			return 2
+		}
+		src, err := io.ReadAll(stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "monkey: %s\n", err)
+			return 1
+		}
+		return formatSource("<stdin>", string(src), *list, false, stdout, stderr)
+	}
+
+	status := 0
+	for _, name := range flags.Args() {
+		src, err := os.ReadFile(name)
+		if err != nil {
+			fmt.Fprintf(stderr, "monkey: %s\n", err)
+			status = 1
+			continue
+		}
+		if formatSource(name, string(src), *list, *write, stdout, stderr) != 0 {
+			status = 1
+		}
+	}
+	return status
+}
+
+// formatSource formats the script called name. The result is printed, unless
+// only the names of unformatted scripts are listed or it is written back.
+func formatSource(
+	name string,
+	src string,
+	list bool,
+	write bool,
+	stdout io.Writer,
+	stderr io.Writer,
+) int {
+
+	formatted, err := format.Source(src)
+	if err != nil {
+		if syntaxErr, ok := err.(*format.SyntaxError); ok {
+			for _, msg := range syntaxErr.Errors {
+				fmt.Fprintf(stderr, "%s:%s\n", name, msg)
+			}
+		} else {
+			fmt.Fprintf(stderr, "monkey: %s\n", err)
+		}
+		return 1
+	}
+
+	if list && formatted != src {
+		fmt.Fprintln(stdout, name)
+	}
+	if write && formatted != src {
+		if err := os.WriteFile(name, []byte(formatted), 0o644); err != nil {
+			fmt.Fprintf(stderr, "monkey: %s\n", err)
+			return 1
+		}
+	}
+	if !list && !write {
+		io.WriteString(stdout, formatted)
+	}
+	return 0
+}
+
+func lintCmd(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	checkList := flags.String("checks", "all", "comma-separated list of checks to run")
+	asJSON := flags.Bool("json", false, "print diagnostics as JSON, one per line")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	checks, err := lint.ParseChecks(*checkList)
+	if err != nil {
+		fmt.Fprintf(stderr, "monkey: %s\n", err)
+		return 2
+	}
+
+	builtins := eval.New(eval.WithCapabilities(eval.AllCapabilities)).BuiltinNames()
+	linter := lint.New(lint.WithChecks(checks), lint.WithGlobals(builtins...))
+
+	if flags.NArg() == 0 {
+		src, err := io.ReadAll(stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "monkey: %s\n", err)
+			return 1
+		}
+		return lintSource(linter, "<stdin>", stripShebang(string(src)), *asJSON, stdout, stderr)
+	}
+
+	status := 0
+	for _, name := range flags.Args() {
+		src, err := readSource(name)
+		if err != nil {
+			fmt.Fprintf(stderr, "monkey: %s\n", err)
+			status = 1
+			continue
+		}
+		if lintSource(linter, name, src, *asJSON, stdout, stderr) != 0 {
+			status = 1
+		}
+	}
+	return status
+}
+
+// lintSource prints the problems linter finds in the script called name,
+// and fails if there are any.
+func lintSource(
+	linter *lint.Linter,
+	name string,
+	src string,
+	asJSON bool,
+	stdout io.Writer,
+	stderr io.Writer,
+) int {
+
+	program, ok := parse(name, src, stderr)
+	if !ok {
+		return 1
+	}
+	diagnostics := linter.Program(program)
+
+	enc := json.NewEncoder(stdout)
+	for _, d := range diagnostics {
+		if asJSON {
+			enc.Encode(struct {
+				File string `json:"file"`
+				lint.Diagnostic
+			}{name, d})
+		} else {
+			fmt.Fprintf(stdout, "%s:%s\n", name, d)
+		}
+	}
+	if len(diagnostics) != 0 {
+		return 1
+	}
+	return 0
+}
+
+func checkCmd(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		src, err := io.ReadAll(stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "monkey: %s\n", err)
+			return 1
+		}
+		program, ok := parse("<stdin>", stripShebang(string(src)), stderr)
+		if !ok || !typeCheck("<stdin>", program, stdout) {
+			return 1
+		}
+		return 0
+	}
+
+	status := 0
+	for _, name := range args {
+		src, err := readSource(name)
+		if err != nil {
+			fmt.Fprintf(stderr, "monkey: %s\n", err)
+			status = 1
+			continue
+		}
+		program, ok := parse(name, src, stderr)
+		if !ok || !typeCheck(name, program, stdout) {
+			status = 1
+		}
+	}
+	return status
+}
+
+// typeCheck prints the type errors of the script called name to w, and
+// reports whether there were none.
+func typeCheck(name string, program *ast.Program, w io.Writer) bool {
+	errors := types.Check(program)
+	for _, err := range errors {
+		fmt.Fprintf(w, "%s:%s\n", name, err)
+	}
+	return len(errors) == 0
+}
+
+// readSource reads the script at path, without its '#!' line.
+func readSource(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return stripShebang(string(b)), nil
+}
+
+// stripShebang blanks out a leading '#!' line rather than removing it, so
+// that positions still match the source.
+func stripShebang(src string) string {
+	if strings.HasPrefix(src, "#!") {
+		if i := strings.IndexByte(src, '\n'); i >= 0 {
+			return src[i:]
+		}
+		return ""
+	}
+	return src
+}
+
+func parse(name, src string, stderr io.Writer) (*ast.Program, bool) {
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		for _, msg := range p.Errors() {
+			fmt.Fprintf(stderr, "%s:%s\n", name, msg)
+		}
+		return nil, false
+	}
+	return program, true
+}
+
+// evaluate optimizes and runs program, importing modules from the given
+// filesystem. The program prints to stdout, where its result is also
+// printed if printResult is set.
+func evaluate(
+	name string,
+	program *ast.Program,
+	modules fs.FS,
+	printResult bool,
+	stdout io.Writer,
+	stderr io.Writer,
+) int {
+
+	optimize.Program(program)
+
+	in := eval.New(
+		eval.WithCapabilities(eval.AllCapabilities),
+		eval.WithRoot("."),
+		eval.WithModules(modules),
+		eval.WithOutput(stdout),
+	)
+	result := in.Eval(program, object.NewEnv())
+
+	if err, ok := result.(*object.Error); ok {
+		fmt.Fprintf(stderr, "%s: error: %s\n", name, err.Message)
+		return 1
+	}
+	if printResult && result != nil {
+		fmt.Fprintln(stdout, result.Inspect())
+	}
+	return 0
+}
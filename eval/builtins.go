@@ -5,101 +5,130 @@ import (
 	"monkey/object"
 )
 
-var builtins = map[string]*object.Builtin{
-	"len": &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
-			if len(args) != 1 {
-				return newError("wrong number of arguments, expected 1")
-			}
+type builtinFunction func(in *Interpreter, args ...object.Object) object.Object
 
-			switch arg := args[0].(type) {
-			case *object.Array:
-				return &object.Integer{Value: int64(len(arg.Elements))}
-			case *object.String:
-				return &object.Integer{Value: int64(len(arg.Value))}
-			default:
-				return newError("argument to `len` not supported")
-			}
-		},
-	},
-	"head": &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
-			if len(args) != 1 {
-				return newError("wrong number of arguments, expected 1")
-			}
-			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("argument must be of type 'ARRAY'")
-			}
+// builtin is a function along with the capabilities it requires in order to
+// be called.
+type builtin struct {
+	fn   builtinFunction
+	caps Capability
+}
+
+var builtins = map[string]builtin{
+	"len": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("wrong number of arguments, expected 1")
+		}
+
+		switch arg := args[0].(type) {
+		case *object.Array:
+			return &object.Integer{Value: int64(len(arg.Elements))}
+		case *object.String:
+			return &object.Integer{Value: int64(len(arg.Value))}
+		default:
+			return newError("argument to `len` not supported")
+		}
+	}},
+	"head": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("wrong number of arguments, expected 1")
+		}
+		if args[0].Type() != object.ARRAY_OBJ {
+			return newError("argument must be of type 'ARRAY'")
+		}
 
-			arr := args[0].(*object.Array)
-			if len(arr.Elements) > 0 {
-				return arr.Elements[0]
-			}
+		arr := args[0].(*object.Array)
+		if len(arr.Elements) > 0 {
+			return arr.Elements[0]
+		}
+		return NULL
+	}},
+	"last": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("wrong number of arguments, expected 1")
+		}
+		if args[0].Type() != object.ARRAY_OBJ {
+			return newError("argument must be of type 'ARRAY'")
+		}
+
+		arr := args[0].(*object.Array)
+		length := len(arr.Elements)
+		if length <= 0 {
 			return NULL
-		},
-	},
-	"last": &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
-			if len(args) != 1 {
-				return newError("wrong number of arguments, expected 1")
-			}
-			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("argument must be of type 'ARRAY'")
-			}
+		}
+		return arr.Elements[length-1]
+	}},
+	"tail": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("wrong number of arguments, expected 1")
+		}
+		if args[0].Type() != object.ARRAY_OBJ {
+			return newError("argument must be of type 'ARRAY'")
+		}
 
-			arr := args[0].(*object.Array)
-			length := len(arr.Elements)
-			if length <= 0 {
-				return NULL
-			}
-			return arr.Elements[length-1]
-		},
-	},
-	"tail": &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
-			if len(args) != 1 {
-				return newError("wrong number of arguments, expected 1")
-			}
-			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("argument must be of type 'ARRAY'")
-			}
+		arr := args[0].(*object.Array)
+		length := len(arr.Elements)
+		if length <= 0 {
+			return NULL
+		}
+		if err := in.alloc(int64(length-1) * arrayElementSize); err != nil {
+			return err
+		}
+		newElements := make([]object.Object, length-1, length-1)
+		copy(newElements, arr.Elements[1:length])
 
-			arr := args[0].(*object.Array)
-			length := len(arr.Elements)
-			if length <= 0 {
-				return NULL
-			}
-			newElements := make([]object.Object, length-1, length-1)
-			copy(newElements, arr.Elements[1:length])
+		return &object.Array{Elements: newElements}
+	}},
+	"append": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if len(args) != 2 {
+			return newError("wrong number of arguments, expected 2")
+		}
+		if args[0].Type() != object.ARRAY_OBJ {
+			return newError("argument must be of type 'ARRAY'")
+		}
 
-			return &object.Array{Elements: newElements}
-		},
-	},
-	"append": &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
-			if len(args) != 2 {
-				return newError("wrong number of arguments, expected 2")
-			}
-			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("argument must be of type 'ARRAY'")
-			}
+		arr := args[0].(*object.Array)
+		length := len(arr.Elements)
 
-			arr := args[0].(*object.Array)
-			length := len(arr.Elements)
+		if err := in.alloc(int64(length+1) * arrayElementSize); err != nil {
+			return err
+		}
+		newElements := make([]object.Object, length+1, length+1)
+		copy(newElements, arr.Elements)
+		newElements[length] = args[1]
 
-			newElements := make([]object.Object, length+1, length+1)
-			copy(newElements, arr.Elements)
-			newElements[length] = args[1]
+		return &object.Array{Elements: newElements}
+	}},
+	"println": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		for _, arg := range args {
+			fmt.Fprintln(in.out, arg.Inspect())
+		}
+		return NULL
+	}},
+}
 
-			return &object.Array{Elements: newElements}
-		},
-	},
-	"println": &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
-			for _, arg := range args {
-				fmt.Println(arg.Inspect())
-			}
-			return NULL
-		},
-	},
+// registerBuiltins adds a set of builtins to the ones available to every
+// interpreter.
+func registerBuiltins(set map[string]builtin) {
+	for name, b := range set {
+		builtins[name] = b
+	}
+}
+
+// checkArgs validates that args are exactly of the given types, reporting
+// errors the same way as the builtins above.
+func checkArgs(args []object.Object, types ...object.ObjectType) *object.Error {
+	if len(args) != len(types) {
+		return newError("wrong number of arguments, expected %d", len(types))
+	}
+	for i, t := range types {
+		if args[i].Type() == t {
+			continue
+		}
+		if len(types) == 1 {
+			return newError("argument must be of type '%s'", t)
+		}
+		return newError("argument %d must be of type '%s'", i+1, t)
+	}
+	return nil
 }
@@ -0,0 +1,345 @@
+package eval
+
+import (
+	"math"
+	"sort"
+
+	"monkey/object"
+)
+
+func init() {
+	registerBuiltins(arrayBuiltins)
+}
+
+var arrayBuiltins = map[string]builtin{
+	"map": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		arr, fn, err := arrayAndFunctionArgs(args)
+		if err != nil {
+			return err
+		}
+
+		elements := make([]object.Object, len(arr.Elements))
+		for i, e := range arr.Elements {
+			result := in.call(fn, e)
+			if isError(result) {
+				return result
+			}
+			elements[i] = result
+		}
+		return in.newArray(elements)
+	}},
+	"filter": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		arr, fn, err := arrayAndFunctionArgs(args)
+		if err != nil {
+			return err
+		}
+
+		elements := []object.Object{}
+		for _, e := range arr.Elements {
+			result := in.call(fn, e)
+			if isError(result) {
+				return result
+			}
+			if isTrue(result) {
+				elements = append(elements, e)
+			}
+		}
+		return in.newArray(elements)
+	}},
+	"reduce": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if len(args) != 3 {
+			return newError("wrong number of arguments, expected 3")
+		}
+		arr, fn, err := arrayAndFunctionArgs(args[:2])
+		if err != nil {
+			return err
+		}
+
+		acc := args[2]
+		for _, e := range arr.Elements {
+			acc = in.call(fn, acc, e)
+			if isError(acc) {
+				return acc
+			}
+		}
+		return acc
+	}},
+	"each": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		arr, fn, err := arrayAndFunctionArgs(args)
+		if err != nil {
+			return err
+		}
+
+		for _, e := range arr.Elements {
+			if result := in.call(fn, e); isError(result) {
+				return result
+			}
+		}
+		return NULL
+	}},
+	"find": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		arr, fn, err := arrayAndFunctionArgs(args)
+		if err != nil {
+			return err
+		}
+
+		for _, e := range arr.Elements {
+			result := in.call(fn, e)
+			if isError(result) {
+				return result
+			}
+			if isTrue(result) {
+				return e
+			}
+		}
+		return NULL
+	}},
+	"any": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		arr, fn, err := arrayAndFunctionArgs(args)
+		if err != nil {
+			return err
+		}
+
+		for _, e := range arr.Elements {
+			result := in.call(fn, e)
+			if isError(result) {
+				return result
+			}
+			if isTrue(result) {
+				return TRUE
+			}
+		}
+		return FALSE
+	}},
+	"all": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		arr, fn, err := arrayAndFunctionArgs(args)
+		if err != nil {
+			return err
+		}
+
+		for _, e := range arr.Elements {
+			result := in.call(fn, e)
+			if isError(result) {
+				return result
+			}
+			if !isTrue(result) {
+				return FALSE
+			}
+		}
+		return TRUE
+	}},
+	"sort": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if len(args) == 2 {
+			arr, fn, err := arrayAndFunctionArgs(args)
+			if err != nil {
+				return err
+			}
+			return in.sortArray(arr, func(a, b object.Object) object.Object {
+				return in.call(fn, a, b)
+			})
+		}
+		if err := checkArgs(args, object.ARRAY_OBJ); err != nil {
+			if len(args) != 1 {
+				return newError("wrong number of arguments, expected 1 or 2")
+			}
+			return err
+		}
+		return in.sortArray(args[0].(*object.Array), less)
+	}},
+	"reverse": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.ARRAY_OBJ); err != nil {
+			return err
+		}
+		arr := args[0].(*object.Array)
+		length := len(arr.Elements)
+
+		elements := make([]object.Object, length)
+		for i, e := range arr.Elements {
+			elements[length-1-i] = e
+		}
+		return in.newArray(elements)
+	}},
+	"zip": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.ARRAY_OBJ, object.ARRAY_OBJ); err != nil {
+			return err
+		}
+		left := args[0].(*object.Array).Elements
+		right := args[1].(*object.Array).Elements
+
+		length := len(left)
+		if len(right) < length {
+			length = len(right)
+		}
+		if err := in.alloc(int64(length) * 3 * arrayElementSize); err != nil {
+			return err
+		}
+
+		elements := make([]object.Object, length)
+		for i := range elements {
+			pair := []object.Object{left[i], right[i]}
+			elements[i] = &object.Array{Elements: pair}
+		}
+		return &object.Array{Elements: elements}
+	}},
+	"flatten": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.ARRAY_OBJ); err != nil {
+			return err
+		}
+
+		// Only one level of nesting is removed.
+		elements := []object.Object{}
+		for _, e := range args[0].(*object.Array).Elements {
+			if nested, ok := e.(*object.Array); ok {
+				elements = append(elements, nested.Elements...)
+			} else {
+				elements = append(elements, e)
+			}
+		}
+		return in.newArray(elements)
+	}},
+	"unique": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.ARRAY_OBJ); err != nil {
+			return err
+		}
+
+		seen := make(map[object.HashKey]bool)
+		elements := []object.Object{}
+		for _, e := range args[0].(*object.Array).Elements {
+			hashable, ok := e.(object.Hashable)
+			if !ok {
+				return newError("argument must only contain hashable elements, got %s",
+					e.Type())
+			}
+			if key := hashable.HashKey(); !seen[key] {
+				seen[key] = true
+				elements = append(elements, e)
+			}
+		}
+		return in.newArray(elements)
+	}},
+	"range": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		var start, end, step int64 = 0, 0, 1
+
+		switch len(args) {
+		case 1:
+			if err := checkArgs(args, object.INT_OBJ); err != nil {
+				return err
+			}
+			end = args[0].(*object.Integer).Value
+		case 2:
+			if err := checkArgs(args, object.INT_OBJ, object.INT_OBJ); err != nil {
+				return err
+			}
+			start = args[0].(*object.Integer).Value
+			end = args[1].(*object.Integer).Value
+		case 3:
+			err := checkArgs(args, object.INT_OBJ, object.INT_OBJ, object.INT_OBJ)
+			if err != nil {
+				return err
+			}
+			start = args[0].(*object.Integer).Value
+			end = args[1].(*object.Integer).Value
+			step = args[2].(*object.Integer).Value
+		default:
+			return newError("wrong number of arguments, expected 1 to 3")
+		}
+		if step == 0 {
+			return newError("argument 3 must not be zero")
+		}
+
+		// The distance between the bounds and the step are taken unsigned,
+		// so that neither overflows whatever the bounds are.
+		var span, stride uint64
+		if step > 0 && end > start {
+			span, stride = uint64(end)-uint64(start), uint64(step)
+		} else if step < 0 && end < start {
+			span, stride = uint64(start)-uint64(end), -uint64(step)
+		}
+		var length int64
+		if span > 0 {
+			n := (span-1)/stride + 1
+			if n > math.MaxInt32 {
+				return newError("range is too large")
+			}
+			length = int64(n)
+		}
+		if err := in.alloc(length * arrayElementSize); err != nil {
+			return err
+		}
+
+		elements := make([]object.Object, length)
+		for i := range elements {
+			elements[i] = &object.Integer{Value: start + int64(i)*step}
+		}
+		return &object.Array{Elements: elements}
+	}},
+}
+
+// call applies fn, which may be a user-defined function or a builtin, to
+// args on behalf of a builtin.
+func (in *Interpreter) call(fn object.Object, args ...object.Object) object.Object {
+	return in.applyFunction(fn, args)
+}
+
+// arrayAndFunctionArgs validates the arguments of builtins taking an array
+// and a function to apply to its elements.
+func arrayAndFunctionArgs(args []object.Object) (*object.Array, object.Object, *object.Error) {
+	if len(args) != 2 {
+		return nil, nil, newError("wrong number of arguments, expected 2")
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return nil, nil, newError("argument 1 must be of type 'ARRAY'")
+	}
+	if !isCallable(args[1]) {
+		return nil, nil, newError("argument 2 must be a function")
+	}
+	return arr, args[1], nil
+}
+
+func isCallable(obj object.Object) bool {
+	t := obj.Type()
+	return t == object.FUNCTION_OBJ || t == object.BUILTIN_OBJ
+}
+
+// sortArray returns a sorted copy of arr, where lessFn reports whether its
+// first argument goes before the second one, or an error.
+func (in *Interpreter) sortArray(
+	arr *object.Array,
+	lessFn func(a, b object.Object) object.Object,
+) object.Object {
+
+	elements := make([]object.Object, len(arr.Elements))
+	copy(elements, arr.Elements)
+
+	var err object.Object
+	sort.SliceStable(elements, func(i, j int) bool {
+		if err != nil {
+			return false
+		}
+		result := lessFn(elements[i], elements[j])
+		if isError(result) {
+			err = result
+			return false
+		}
+		return isTrue(result)
+	})
+	if err != nil {
+		return err
+	}
+	return in.newArray(elements)
+}
+
+// less orders integers and strings, which are the only values sorted
+// without a comparator.
+func less(a, b object.Object) object.Object {
+	switch {
+	case a.Type() == object.INT_OBJ && b.Type() == object.INT_OBJ:
+		return booleanObject(a.(*object.Integer).Value < b.(*object.Integer).Value)
+	case a.Type() == object.STRING_OBJ && b.Type() == object.STRING_OBJ:
+		return booleanObject(a.(*object.String).Value < b.(*object.String).Value)
+	default:
+		return newError("cannot compare %s and %s without a comparator",
+			a.Type(), b.Type())
+	}
+}
@@ -0,0 +1,83 @@
+package eval
+
+import "testing"
+
+func TestArrayBuiltins(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected interface{}
+	}{
+		{`map([1, 2, 3], fn(x) { x * 2 })`, []interface{}{2, 4, 6}},
+		{`map(["a", "bc"], len)`, []interface{}{1, 2}},
+		{`map([], fn(x) { x })`, []interface{}{}},
+		{`map([1], fn(x, y) { x })`, errorMessage("wrong number of arguments, expected 2, got 1")},
+		{`map([1, true], fn(x) { x + 1 })`, errorMessage("type mismatch: BOOLEAN + INTEGER")},
+		{`map(1, fn(x) { x })`, errorMessage("argument 1 must be of type 'ARRAY'")},
+		{`map([1], 1)`, errorMessage("argument 2 must be a function")},
+		{`filter([1, 2, 3, 4], fn(x) { x > 2 })`, []interface{}{3, 4}},
+		{`reduce([1, 2, 3, 4], fn(acc, x) { acc + x }, 0)`, 10},
+		{`reduce([], fn(acc, x) { acc + x }, "init")`, "init"},
+		{`reduce([1], fn(acc, x) { acc + x })`, errorMessage("wrong number of arguments, expected 3")},
+		{`let sum = 0; each([1, 2], fn(x) { x })`, nil},
+		{`find([1, 2, 3], fn(x) { x > 1 })`, 2},
+		{`find([1, 2, 3], fn(x) { x > 5 })`, nil},
+		{`any([1, 2, 3], fn(x) { x > 2 })`, true},
+		{`any([], fn(x) { true })`, false},
+		{`all([1, 2, 3], fn(x) { x > 0 })`, true},
+		{`all([1, 2, 3], fn(x) { x > 1 })`, false},
+		{`sort([3, 1, 2])`, []interface{}{1, 2, 3}},
+		{`sort(["b", "c", "a"])`, []interface{}{"a", "b", "c"}},
+		{`sort([3, 1, 2], fn(a, b) { a > b })`, []interface{}{3, 2, 1}},
+		{`sort([[2, "b"], [1, "a"]], fn(a, b) { a[0] < b[0] })`, []interface{}{
+			[]interface{}{1, "a"}, []interface{}{2, "b"},
+		}},
+		{`sort([1, "a"])`, errorMessage("cannot compare STRING and INTEGER without a comparator")},
+		{`sort([1, 2], fn(a, b) { a + true })`, errorMessage("type mismatch: INTEGER + BOOLEAN")},
+		{`sort()`, errorMessage("wrong number of arguments, expected 1 or 2")},
+		{`reverse([1, 2, 3])`, []interface{}{3, 2, 1}},
+		{`zip([1, 2, 3], ["a", "b"])`, []interface{}{
+			[]interface{}{1, "a"}, []interface{}{2, "b"},
+		}},
+		{`flatten([1, [2, 3], [[4]], []])`, []interface{}{1, 2, 3, []interface{}{4}}},
+		{`unique([1, 2, 1, "a", "a", true, 2])`, []interface{}{1, 2, "a", true}},
+		{`unique([[1], [1]])`, errorMessage("argument must only contain hashable elements, got ARRAY")},
+		{`range(4)`, []interface{}{0, 1, 2, 3}},
+		{`range(2, 5)`, []interface{}{2, 3, 4}},
+		{`range(0, 10, 3)`, []interface{}{0, 3, 6, 9}},
+		{`range(5, 0, -2)`, []interface{}{5, 3, 1}},
+		{`range(5, 0)`, []interface{}{}},
+		{`range(0, 5, 0)`, errorMessage("argument 3 must not be zero")},
+		{`range(-2, 3, 2)`, []interface{}{-2, 0, 2}},
+		{`range(3, -4, -3)`, []interface{}{3, 0, -3}},
+		{`range(0, 5, -1)`, []interface{}{}},
+		{`range(9223372036854775806, 9223372036854775807)`, []interface{}{9223372036854775806}},
+		{`range(0, 9223372036854775807)`, errorMessage("range is too large")},
+		{`range(-9223372036854775807 - 1, 9223372036854775807)`, errorMessage("range is too large")},
+		{`range(9223372036854775807, -9223372036854775807 - 1, -1)`, errorMessage("range is too large")},
+		{`range(-9223372036854775807 - 1, 9223372036854775807, 9223372036854775807)`,
+			[]interface{}{-9223372036854775808, -1, 9223372036854775806}},
+		{`range(9223372036854775807, -9223372036854775807 - 1, -9223372036854775807 - 1)`,
+			[]interface{}{9223372036854775807, -1}},
+		{`range()`, errorMessage("wrong number of arguments, expected 1 to 3")},
+	} {
+		testObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestArrayBuiltinsClosures(t *testing.T) {
+	input := `
+let offset = 10;
+let numbers = range(1, 6);
+let odd = filter(numbers, fn(x) { x - (x / 2) * 2 == 1 });
+reduce(map(odd, fn(x) { x + offset }), fn(acc, x) { acc + x }, 0);
+`
+	testIntegerObject(t, testEval(input), 39)
+}
+
+func TestRangeLimits(t *testing.T) {
+	in := New(WithLimits(Limits{MaxValueSize: 1024}))
+
+	testErrorObject(t, testEvalWith(in, "range(1000000000)"),
+		"resource limit exceeded: value of 16000000000 bytes exceeds 1024")
+	testErrorObject(t, testEvalWith(in, "range(0, 9223372036854775807)"), "range is too large")
+}
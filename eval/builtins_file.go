@@ -0,0 +1,199 @@
+package eval
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"monkey/object"
+)
+
+func init() {
+	registerBuiltins(fileBuiltins)
+}
+
+// WithRoot confines file builtins to dir. Paths given by scripts are relative
+// to it, and may not lead out of it, whether through '..' or symbolic links.
+// Without a root, file builtins always fail.
+func WithRoot(dir string) Option {
+	return func(in *Interpreter) {
+		in.root = dir
+	}
+}
+
+var fileBuiltins = map[string]builtin{
+	"read_file": {caps: CapFileRead, fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.STRING_OBJ); err != nil {
+			return err
+		}
+		path, err := in.resolvePath(stringArg(args, 0))
+		if err != nil {
+			return err
+		}
+
+		b, ioErr := os.ReadFile(path)
+		if ioErr != nil {
+			return fileError("read", stringArg(args, 0), ioErr)
+		}
+		return in.newString(string(b))
+	}},
+	// Lines are split on '\n', with any '\r' before it removed. A final
+	// newline does not start another line.
+	"read_lines": {caps: CapFileRead, fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.STRING_OBJ); err != nil {
+			return err
+		}
+		path, err := in.resolvePath(stringArg(args, 0))
+		if err != nil {
+			return err
+		}
+
+		b, ioErr := os.ReadFile(path)
+		if ioErr != nil {
+			return fileError("read", stringArg(args, 0), ioErr)
+		}
+		content := strings.TrimSuffix(string(b), "\n")
+		if content == "" {
+			return in.newArray([]object.Object{})
+		}
+
+		lines := strings.Split(content, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimSuffix(line, "\r")
+		}
+		return in.newStringArray(lines)
+	}},
+	// The file is created if needed, and replaced otherwise.
+	"write_file": {caps: CapFileWrite, fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+			return err
+		}
+		path, err := in.resolvePath(stringArg(args, 0))
+		if err != nil {
+			return err
+		}
+
+		if ioErr := os.WriteFile(path, []byte(stringArg(args, 1)), 0o644); ioErr != nil {
+			return fileError("write", stringArg(args, 0), ioErr)
+		}
+		return NULL
+	}},
+	// Entries are sorted by name.
+	"list_dir": {caps: CapFileRead, fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.STRING_OBJ); err != nil {
+			return err
+		}
+		path, err := in.resolvePath(stringArg(args, 0))
+		if err != nil {
+			return err
+		}
+
+		entries, ioErr := os.ReadDir(path)
+		if ioErr != nil {
+			return fileError("list", stringArg(args, 0), ioErr)
+		}
+		names := make([]string, len(entries))
+		for i, entry := range entries {
+			names[i] = entry.Name()
+		}
+		return in.newStringArray(names)
+	}},
+	"exists": {caps: CapFileRead, fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.STRING_OBJ); err != nil {
+			return err
+		}
+		path, err := in.resolvePath(stringArg(args, 0))
+		if err != nil {
+			return err
+		}
+
+		_, ioErr := os.Stat(path)
+		if errors.Is(ioErr, fs.ErrNotExist) {
+			return FALSE
+		}
+		if ioErr != nil {
+			return fileError("check", stringArg(args, 0), ioErr)
+		}
+		return TRUE
+	}},
+}
+
+// maxLinks is the number of links to missing files resolvePath follows before
+// giving up, since they may lead back to themselves.
+const maxLinks = 40
+
+// resolvePath returns the location of name within the root directory. The
+// deepest existing part of it has its symbolic links resolved, so that the
+// result is known to stay within the root.
+func (in *Interpreter) resolvePath(name string) (string, *object.Error) {
+	if in.root == "" {
+		return "", newError("no root directory configured for file access")
+	}
+	root, err := filepath.EvalSymlinks(in.root)
+	if err != nil {
+		return "", fileError("open", "root directory", err)
+	}
+
+	clean := filepath.Clean(name)
+	if filepath.IsAbs(clean) || !isWithin(".", clean) {
+		return "", newError("path %q is outside of the root directory", name)
+	}
+	path := filepath.Join(root, clean)
+
+	// Files that do not exist yet, such as the target of `write_file`, are
+	// checked through their closest existing parent. Links to files that do
+	// not exist are followed by hand, as writing to them creates their
+	// target.
+	existing, rest := path, ""
+	for links := 0; ; {
+		resolved, err := filepath.EvalSymlinks(existing)
+		if err == nil {
+			path = filepath.Join(resolved, rest)
+			break
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", fileError("open", name, err)
+		}
+
+		if info, err := os.Lstat(existing); err == nil && info.Mode()&fs.ModeSymlink != 0 {
+			if links++; links > maxLinks {
+				return "", newError("could not open %q: too many links", name)
+			}
+			target, err := os.Readlink(existing)
+			if err != nil {
+				return "", fileError("open", name, err)
+			}
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(existing), target)
+			}
+			existing = target
+			continue
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = filepath.Dir(existing)
+	}
+	if !isWithin(root, path) {
+		return "", newError("path %q is outside of the root directory", name)
+	}
+	return path, nil
+}
+
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// fileError reports a failed operation on name without revealing where the
+// root directory is.
+func fileError(op, name string, err error) *object.Error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+	return newError("could not %s %q: %s", op, name, err)
+}
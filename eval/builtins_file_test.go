@@ -0,0 +1,88 @@
+package eval
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileBuiltins(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+
+	writeTestFile(t, filepath.Join(root, "config.txt"), "a = 1\r\nb = 2\n")
+	writeTestFile(t, filepath.Join(root, "data", "empty.txt"), "")
+	writeTestFile(t, filepath.Join(outside, "secret.txt"), "secret")
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("config.txt", filepath.Join(root, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "pwned.txt"), filepath.Join(root, "dangling")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("data/later.txt", filepath.Join(root, "pending")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("missing/../cycle", filepath.Join(root, "cycle")); err != nil {
+		t.Fatal(err)
+	}
+
+	in := New(WithCapabilities(CapFileRead|CapFileWrite), WithRoot(root))
+
+	for _, tt := range []struct {
+		input    string
+		expected interface{}
+	}{
+		{`read_file("config.txt")`, "a = 1\r\nb = 2\n"},
+		{`read_file("./data/../config.txt")`, "a = 1\r\nb = 2\n"},
+		{`read_file("link.txt")`, "a = 1\r\nb = 2\n"},
+		{`read_lines("config.txt")`, []interface{}{"a = 1", "b = 2"}},
+		{`read_lines("data/empty.txt")`, []interface{}{}},
+		{`read_file("missing.txt")`, errorMessage(`could not read "missing.txt": no such file or directory`)},
+		{`read_file("data")`, errorMessage(`could not read "data": is a directory`)},
+		{`read_file("../secret.txt")`, errorMessage(`path "../secret.txt" is outside of the root directory`)},
+		{`read_file("/etc/passwd")`, errorMessage(`path "/etc/passwd" is outside of the root directory`)},
+		{`read_file("escape/secret.txt")`, errorMessage(`path "escape/secret.txt" is outside of the root directory`)},
+		{`write_file("escape/new.txt", "x")`, errorMessage(`path "escape/new.txt" is outside of the root directory`)},
+		{`exists("escape")`, errorMessage(`path "escape" is outside of the root directory`)},
+		{`write_file("dangling", "x")`, errorMessage(`path "dangling" is outside of the root directory`)},
+		{`write_file("pending", "later"); read_file("data/later.txt")`, "later"},
+		{`write_file("cycle", "x")`, errorMessage(`could not open "cycle": too many links`)},
+		{`list_dir("")`, []interface{}{"config.txt", "cycle", "dangling", "data", "escape", "link.txt", "pending"}},
+		{`list_dir("data")`, []interface{}{"empty.txt", "later.txt"}},
+		{`exists("config.txt")`, true},
+		{`exists("nothing/here.txt")`, false},
+		{`write_file("report.txt", "ok"); read_file("report.txt")`, "ok"},
+		{`write_file("nothing/here.txt", "x")`, errorMessage(`could not write "nothing/here.txt": no such file or directory`)},
+	} {
+		testObject(t, testEvalWith(in, tt.input), tt.expected)
+	}
+
+	for _, name := range []string{"new.txt", "pwned.txt"} {
+		if _, err := os.Stat(filepath.Join(outside, name)); err == nil {
+			t.Errorf("write_file created %s outside of the root", name)
+		}
+	}
+}
+
+func TestFileBuiltinsWithoutRoot(t *testing.T) {
+	in := New(WithCapabilities(CapFileRead))
+
+	testObject(t, testEvalWith(in, `read_file("a.txt")`),
+		errorMessage("no root directory configured for file access"))
+	testObject(t, testEvalWith(in, `write_file("a.txt", "")`),
+		errorMessage("permission denied: `write_file` requires file write capability"))
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,194 @@
+package eval
+
+import "monkey/object"
+
+func init() {
+	registerBuiltins(hashBuiltins)
+}
+
+// Hashes cannot be modified by scripts, so builtins changing their contents
+// return new hashes instead. Every builtin iterating over a hash does so in
+// insertion order.
+var hashBuiltins = map[string]builtin{
+	"keys": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.HASH_OBJ); err != nil {
+			return err
+		}
+		pairs := args[0].(*object.Hash).OrderedPairs()
+
+		elements := make([]object.Object, len(pairs))
+		for i, pair := range pairs {
+			elements[i] = pair.Key
+		}
+		return in.newArray(elements)
+	}},
+	"values": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.HASH_OBJ); err != nil {
+			return err
+		}
+		pairs := args[0].(*object.Hash).OrderedPairs()
+
+		elements := make([]object.Object, len(pairs))
+		for i, pair := range pairs {
+			elements[i] = pair.Value
+		}
+		return in.newArray(elements)
+	}},
+	"items": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.HASH_OBJ); err != nil {
+			return err
+		}
+		pairs := args[0].(*object.Hash).OrderedPairs()
+
+		if err := in.alloc(int64(len(pairs)) * 3 * arrayElementSize); err != nil {
+			return err
+		}
+		elements := make([]object.Object, len(pairs))
+		for i, pair := range pairs {
+			item := []object.Object{pair.Key, pair.Value}
+			elements[i] = &object.Array{Elements: item}
+		}
+		return &object.Array{Elements: elements}
+	}},
+	"has": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if len(args) != 2 {
+			return newError("wrong number of arguments, expected 2")
+		}
+		hash, ok := args[0].(*object.Hash)
+		if !ok {
+			return newError("argument 1 must be of type 'HASH'")
+		}
+		key, err := hashKey(args[1])
+		if err != nil {
+			return err
+		}
+		_, ok = hash.Pairs[key]
+
+		return booleanObject(ok)
+	}},
+	"get": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if len(args) != 2 && len(args) != 3 {
+			return newError("wrong number of arguments, expected 2 or 3")
+		}
+		hash, ok := args[0].(*object.Hash)
+		if !ok {
+			return newError("argument 1 must be of type 'HASH'")
+		}
+		key, err := hashKey(args[1])
+		if err != nil {
+			return err
+		}
+
+		if pair, ok := hash.Pairs[key]; ok {
+			return pair.Value
+		}
+		if len(args) == 3 {
+			return args[2]
+		}
+		return NULL
+	}},
+	"set": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if len(args) != 3 {
+			return newError("wrong number of arguments, expected 3")
+		}
+		hash, ok := args[0].(*object.Hash)
+		if !ok {
+			return newError("argument 1 must be of type 'HASH'")
+		}
+		key, err := hashKey(args[1])
+		if err != nil {
+			return err
+		}
+
+		if err := in.alloc(int64(len(hash.Pairs)+1) * hashPairSize); err != nil {
+			return err
+		}
+		result := hash.Copy()
+		result.Set(key, object.HashPair{Key: args[1], Value: args[2]})
+
+		return result
+	}},
+	"delete": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if len(args) != 2 {
+			return newError("wrong number of arguments, expected 2")
+		}
+		hash, ok := args[0].(*object.Hash)
+		if !ok {
+			return newError("argument 1 must be of type 'HASH'")
+		}
+		key, err := hashKey(args[1])
+		if err != nil {
+			return err
+		}
+
+		if err := in.alloc(int64(len(hash.Pairs)) * hashPairSize); err != nil {
+			return err
+		}
+		result := hash.Copy()
+		result.Delete(key)
+
+		return result
+	}},
+	"merge": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if len(args) == 0 {
+			return newError("wrong number of arguments, expected at least 1")
+		}
+
+		size := 0
+		for i, arg := range args {
+			hash, ok := arg.(*object.Hash)
+			if !ok {
+				return newError("argument %d must be of type 'HASH'", i+1)
+			}
+			size += len(hash.Pairs)
+		}
+
+		// Keys present in several hashes take the value of the last one,
+		// while keeping the position of the first.
+		result, err := in.newHash(size)
+		if err != nil {
+			return err
+		}
+		for _, arg := range args {
+			for _, key := range arg.(*object.Hash).Keys {
+				result.Set(key, arg.(*object.Hash).Pairs[key])
+			}
+		}
+		return result
+	}},
+	"from_pairs": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.ARRAY_OBJ); err != nil {
+			return err
+		}
+		elements := args[0].(*object.Array).Elements
+
+		result, err := in.newHash(len(elements))
+		if err != nil {
+			return err
+		}
+		for _, e := range elements {
+			pair, ok := e.(*object.Array)
+			if !ok || len(pair.Elements) != 2 {
+				return newError("argument must only contain arrays of 2 elements")
+			}
+			key, err := hashKey(pair.Elements[0])
+			if err != nil {
+				return err
+			}
+			result.Set(key, object.HashPair{
+				Key:   pair.Elements[0],
+				Value: pair.Elements[1],
+			})
+		}
+		return result
+	}},
+}
+
+// hashKey returns the key obj is stored under, if it can be used as one.
+func hashKey(obj object.Object) (object.HashKey, *object.Error) {
+	hashable, ok := obj.(object.Hashable)
+	if !ok {
+		return object.HashKey{}, newError("invalid as hash key: %s", obj.Type())
+	}
+	return hashable.HashKey(), nil
+}
@@ -0,0 +1,54 @@
+package eval
+
+import "testing"
+
+func TestHashBuiltins(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected interface{}
+	}{
+		{`keys({"c": 1, "a": 2, "b": 3})`, []interface{}{"c", "a", "b"}},
+		{`values({"c": 1, "a": 2, "b": 3})`, []interface{}{1, 2, 3}},
+		{`items({"a": 1, 2: true})`, []interface{}{
+			[]interface{}{"a", 1}, []interface{}{2, true},
+		}},
+		{`keys([])`, errorMessage("argument must be of type 'HASH'")},
+		{`has({"a": 1}, "a")`, true},
+		{`has({"a": 1}, "b")`, false},
+		{`has({"a": 1}, [])`, errorMessage("invalid as hash key: ARRAY")},
+		{`get({"a": 1}, "a")`, 1},
+		{`get({"a": 1}, "b")`, nil},
+		{`get({"a": 1}, "b", 0)`, 0},
+		{`get([], "b")`, errorMessage("argument 1 must be of type 'HASH'")},
+		{`let h = {"a": 1}; let g = set(h, "b", 2); [keys(h), values(g)]`, []interface{}{
+			[]interface{}{"a"}, []interface{}{1, 2},
+		}},
+		{`keys(set({"a": 1, "b": 2}, "a", 3))`, []interface{}{"a", "b"}},
+		{`let h = {"a": 1, "b": 2}; [keys(delete(h, "a")), keys(h)]`, []interface{}{
+			[]interface{}{"b"}, []interface{}{"a", "b"},
+		}},
+		{`keys(delete({"a": 1}, "z"))`, []interface{}{"a"}},
+		{`items(merge({"a": 1, "b": 2}, {"c": 3, "a": 4}))`, []interface{}{
+			[]interface{}{"a", 4}, []interface{}{"b", 2}, []interface{}{"c", 3},
+		}},
+		{`merge({}, 1)`, errorMessage("argument 2 must be of type 'HASH'")},
+		{`items(from_pairs([["b", 1], ["a", 2]]))`, []interface{}{
+			[]interface{}{"b", 1}, []interface{}{"a", 2},
+		}},
+		{`from_pairs([["b", 1, 2]])`, errorMessage("argument must only contain arrays of 2 elements")},
+		{`from_pairs([[fn() {}, 1]])`, errorMessage("invalid as hash key: FUNCTION")},
+	} {
+		testObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestHashIterationOrder(t *testing.T) {
+	input := `{"z": 1, "y": 2, "x": 3, "w": 4, "v": 5}`
+
+	for i := 0; i < 10; i++ {
+		evaluated := testEval(input)
+		if evaluated.Inspect() != "{z: 1, y: 2, x: 3, w: 4, v: 5}" {
+			t.Fatalf("Hash not in insertion order. Got %s", evaluated.Inspect())
+		}
+	}
+}
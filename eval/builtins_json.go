@@ -0,0 +1,237 @@
+package eval
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"monkey/object"
+)
+
+func init() {
+	registerBuiltins(jsonBuiltins)
+}
+
+var jsonBuiltins = map[string]builtin{
+	"json_parse": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.STRING_OBJ); err != nil {
+			return err
+		}
+		dec := json.NewDecoder(strings.NewReader(stringArg(args, 0)))
+		dec.UseNumber()
+
+		value := in.decodeJSON(dec)
+		if isError(value) {
+			return value
+		}
+		if _, err := dec.Token(); err != io.EOF {
+			return newError("invalid JSON: unexpected data after top-level value")
+		}
+		return value
+	}},
+	// The optional indent is either a number of spaces or a string of
+	// whitespace, which puts every element of arrays and hashes on a line
+	// of its own.
+	"json_stringify": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if len(args) != 1 && len(args) != 2 {
+			return newError("wrong number of arguments, expected 1 or 2")
+		}
+
+		enc := &jsonEncoder{in: in}
+		if len(args) == 2 {
+			switch arg := args[1].(type) {
+			case *object.Integer:
+				if arg.Value < 0 || arg.Value > 10 {
+					return newError("argument 2 must be between 0 and 10")
+				}
+				enc.indent = strings.Repeat(" ", int(arg.Value))
+			case *object.String:
+				if strings.Trim(arg.Value, " \t\n\r") != "" {
+					return newError("argument 2 must only contain whitespace")
+				}
+				enc.indent = arg.Value
+			default:
+				return newError("argument 2 must be of type 'INTEGER' or 'STRING'")
+			}
+			enc.indented = true
+		}
+
+		if err := enc.encode(args[0], 0); err != nil {
+			return err
+		}
+		return in.newString(enc.buf.String())
+	}},
+}
+
+// decodeJSON reads the next value from dec. Objects become hashes with keys
+// in the same order as in the source.
+func (in *Interpreter) decodeJSON(dec *json.Decoder) object.Object {
+	tok, err := dec.Token()
+	if err != nil {
+		return jsonError(err)
+	}
+
+	switch tok := tok.(type) {
+	case json.Delim:
+		if tok == '[' {
+			elements := []object.Object{}
+			for dec.More() {
+				e := in.decodeJSON(dec)
+				if isError(e) {
+					return e
+				}
+				elements = append(elements, e)
+			}
+			if _, err := dec.Token(); err != nil {
+				return jsonError(err)
+			}
+			return in.newArray(elements)
+		}
+
+		hash := object.NewHash()
+		for dec.More() {
+			k, err := dec.Token()
+			if err != nil {
+				return jsonError(err)
+			}
+			key := in.newString(k.(string))
+			if isError(key) {
+				return key
+			}
+			value := in.decodeJSON(dec)
+			if isError(value) {
+				return value
+			}
+			hk := key.(*object.String).HashKey()
+			hash.Set(hk, object.HashPair{Key: key, Value: value})
+		}
+		if _, err := dec.Token(); err != nil {
+			return jsonError(err)
+		}
+		if err := in.alloc(int64(len(hash.Pairs)) * hashPairSize); err != nil {
+			return err
+		}
+		return hash
+	case json.Number:
+		value, err := strconv.ParseInt(tok.String(), 10, 64)
+		if err != nil {
+			return newError("invalid JSON: number %s is not an integer", tok)
+		}
+		return &object.Integer{Value: value}
+	case string:
+		return in.newString(tok)
+	case bool:
+		return booleanObject(tok)
+	default:
+		return NULL
+	}
+}
+
+func jsonError(err error) *object.Error {
+	if err == io.EOF {
+		return newError("invalid JSON: unexpected end of input")
+	}
+	return newError("invalid JSON: %s", err)
+}
+
+// jsonEncoder writes values as JSON, checking the size of the output against
+// the limits of the interpreter as it grows.
+type jsonEncoder struct {
+	in  *Interpreter
+	buf bytes.Buffer
+	// indented is set when elements of arrays and hashes go on lines of
+	// their own, preceded by indent once for every level of nesting.
+	indented bool
+	indent   string
+}
+
+// encode writes obj at the given depth of nesting. Only hashes with string
+// keys can be encoded, and functions cannot be encoded at all.
+func (e *jsonEncoder) encode(obj object.Object, depth int) *object.Error {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		e.buf.WriteString(strconv.FormatInt(obj.Value, 10))
+	case *object.Boolean:
+		e.buf.WriteString(strconv.FormatBool(obj.Value))
+	case *object.Null:
+		e.buf.WriteString("null")
+	case *object.String:
+		// Unlike json.Marshal, leave characters such as '<' unescaped.
+		enc := json.NewEncoder(&e.buf)
+		enc.SetEscapeHTML(false)
+		enc.Encode(obj.Value)
+		e.buf.Truncate(e.buf.Len() - 1)
+	case *object.Array:
+		e.buf.WriteByte('[')
+		for i, el := range obj.Elements {
+			if i > 0 {
+				e.buf.WriteByte(',')
+			}
+			if err := e.newline(depth + 1); err != nil {
+				return err
+			}
+			if err := e.encode(el, depth+1); err != nil {
+				return err
+			}
+		}
+		if len(obj.Elements) > 0 {
+			if err := e.newline(depth); err != nil {
+				return err
+			}
+		}
+		e.buf.WriteByte(']')
+	case *object.Hash:
+		pairs := obj.OrderedPairs()
+		e.buf.WriteByte('{')
+		for i, pair := range pairs {
+			if i > 0 {
+				e.buf.WriteByte(',')
+			}
+			if pair.Key.Type() != object.STRING_OBJ {
+				return newError("cannot encode hash key of type %s as JSON",
+					pair.Key.Type())
+			}
+			if err := e.newline(depth + 1); err != nil {
+				return err
+			}
+			if err := e.encode(pair.Key, depth+1); err != nil {
+				return err
+			}
+			e.buf.WriteByte(':')
+			if e.indented {
+				e.buf.WriteByte(' ')
+			}
+			if err := e.encode(pair.Value, depth+1); err != nil {
+				return err
+			}
+		}
+		if len(pairs) > 0 {
+			if err := e.newline(depth); err != nil {
+				return err
+			}
+		}
+		e.buf.WriteByte('}')
+	default:
+		return newError("cannot encode %s as JSON", obj.Type())
+	}
+	return e.in.fits(int64(e.buf.Len()))
+}
+
+// newline starts a new line indented for the given depth, if elements go on
+// lines of their own, after checking that there is room for it.
+func (e *jsonEncoder) newline(depth int) *object.Error {
+	if !e.indented {
+		return nil
+	}
+	size := int64(e.buf.Len()) + 1 + int64(len(e.indent))*int64(depth)
+	if err := e.in.fits(size); err != nil {
+		return err
+	}
+	e.buf.WriteByte('\n')
+	for i := 0; i < depth; i++ {
+		e.buf.WriteString(e.indent)
+	}
+	return nil
+}
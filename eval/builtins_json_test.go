@@ -0,0 +1,79 @@
+package eval
+
+import (
+	"testing"
+
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+)
+
+func TestJSONParse(t *testing.T) {
+	for _, tt := range []struct {
+		json     string
+		input    string
+		expected interface{}
+	}{
+		{`42`, `json_parse(s)`, 42},
+		{`-7`, `json_parse(s)`, -7},
+		{`"ab\"c"`, `json_parse(s)`, `ab"c`},
+		{`true`, `json_parse(s)`, true},
+		{`null`, `json_parse(s)`, nil},
+		{`[1, ["a", false], null]`, `json_parse(s)`, []interface{}{
+			1, []interface{}{"a", false}, nil,
+		}},
+		{`{"b": 1, "a": {"c": []}}`, `keys(json_parse(s))`, []interface{}{"b", "a"}},
+		{`{"a": {"c": [1]}}`, `json_parse(s)["a"]["c"]`, []interface{}{1}},
+		{`1.5`, `json_parse(s)`, errorMessage("invalid JSON: number 1.5 is not an integer")},
+		{``, `json_parse(s)`, errorMessage("invalid JSON: unexpected end of input")},
+		{`[1,`, `json_parse(s)`, errorMessage("invalid JSON: unexpected end of JSON input")},
+		{`{"a" 1}`, `json_parse(s)`, errorMessage("invalid JSON: invalid character '1' after object key")},
+		{`1 2`, `json_parse(s)`, errorMessage("invalid JSON: unexpected data after top-level value")},
+		{``, `json_parse(1)`, errorMessage("argument must be of type 'STRING'")},
+		{`{"k": [1, "v", null]}`, `json_stringify(json_parse(s))`, `{"k":[1,"v",null]}`},
+	} {
+		env := object.NewEnv()
+		env.Insert("s", &object.String{Value: tt.json})
+
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+		testObject(t, New().Eval(program, env), tt.expected)
+	}
+}
+
+func TestJSONStringify(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected interface{}
+	}{
+		{`json_stringify(42)`, "42"},
+		{`json_stringify("<a & b>")`, `"<a & b>"`},
+		{`json_stringify([1, true, "x", if (false) { 1 }])`, `[1,true,"x",null]`},
+		{`json_stringify({"b": 1, "a": [{}]})`, `{"b":1,"a":[{}]}`},
+		{`json_stringify({"a": [1, 2]}, 2)`, "{\n  \"a\": [\n    1,\n    2\n  ]\n}"},
+		{"json_stringify([1], \"\t\")", "[\n\t1\n]"},
+		{`json_stringify({1: 2})`, errorMessage("cannot encode hash key of type INTEGER as JSON")},
+		{`json_stringify([fn(x) { x }])`, errorMessage("cannot encode FUNCTION as JSON")},
+		{`json_stringify(1, true)`, errorMessage("argument 2 must be of type 'INTEGER' or 'STRING'")},
+		{`json_stringify([1], "--")`, errorMessage("argument 2 must only contain whitespace")},
+		{"json_stringify({\"a\": {}, \"b\": []}, \" \t\")", "{\n \t\"a\": {},\n \t\"b\": []\n}"},
+		{`json_stringify([[1]], 0)`, "[\n[\n1\n]\n]"},
+		{`json_stringify()`, errorMessage("wrong number of arguments, expected 1 or 2")},
+	} {
+		testObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestJSONStringifyLimits(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected string
+	}{
+		{`let s = repeat("a", 30); json_stringify([s, s, s])`,
+			"resource limit exceeded: value of 66 bytes exceeds 50"},
+		{`json_stringify([[[1]]], repeat(" ", 20))`,
+			"resource limit exceeded: value of 64 bytes exceeds 50"},
+	} {
+		in := New(WithLimits(Limits{MaxValueSize: 50}))
+		testObject(t, testEvalWith(in, tt.input), errorMessage(tt.expected))
+	}
+}
@@ -0,0 +1,264 @@
+package eval
+
+import (
+	"math"
+
+	"monkey/object"
+)
+
+func init() {
+	registerBuiltins(mathBuiltins)
+}
+
+// Monkey only has integers, so every math builtin works on them. `floor` and
+// `ceil` divide their arguments, rounding the quotient down or up, where the
+// division operator truncates it towards zero.
+var mathBuiltins = map[string]builtin{
+	"abs": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.INT_OBJ); err != nil {
+			return err
+		}
+		value := intArg(args, 0)
+		if value == math.MinInt64 {
+			return newError("integer overflow")
+		}
+		if value < 0 {
+			return &object.Integer{Value: -value}
+		}
+		return args[0]
+	}},
+	"min": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		return extremum(args, func(a, b int64) bool { return a < b })
+	}},
+	"max": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		return extremum(args, func(a, b int64) bool { return a > b })
+	}},
+	"pow": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.INT_OBJ, object.INT_OBJ); err != nil {
+			return err
+		}
+		base, exp := intArg(args, 0), intArg(args, 1)
+		if exp < 0 {
+			return newError("argument 2 must not be negative")
+		}
+
+		// Squaring the base only overflows when the result would as well.
+		result, ok := int64(1), true
+		for exp > 0 {
+			if exp&1 == 1 {
+				if result, ok = multiply(result, base); !ok {
+					return newError("integer overflow")
+				}
+			}
+			if exp >>= 1; exp > 0 {
+				if base, ok = multiply(base, base); !ok {
+					return newError("integer overflow")
+				}
+			}
+		}
+		return &object.Integer{Value: result}
+	}},
+	// The square root is rounded down.
+	"sqrt": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.INT_OBJ); err != nil {
+			return err
+		}
+		value := intArg(args, 0)
+		if value < 0 {
+			return newError("argument must not be negative")
+		}
+
+		// Correct the floating point estimate, which can be off by one for
+		// large values.
+		root := int64(math.Sqrt(float64(value)))
+		for root*root > value {
+			root--
+		}
+		for (root+1)*(root+1) > 0 && (root+1)*(root+1) <= value {
+			root++
+		}
+		return &object.Integer{Value: root}
+	}},
+	"floor": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		a, b, err := divisionArgs(args)
+		if err != nil {
+			return err
+		}
+		q := a / b
+		if a%b != 0 && (a < 0) != (b < 0) {
+			q--
+		}
+		return &object.Integer{Value: q}
+	}},
+	"ceil": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		a, b, err := divisionArgs(args)
+		if err != nil {
+			return err
+		}
+		q := a / b
+		if a%b != 0 && (a < 0) == (b < 0) {
+			q++
+		}
+		return &object.Integer{Value: q}
+	}},
+	"clamp": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		err := checkArgs(args, object.INT_OBJ, object.INT_OBJ, object.INT_OBJ)
+		if err != nil {
+			return err
+		}
+		value, lo, hi := intArg(args, 0), intArg(args, 1), intArg(args, 2)
+		if lo > hi {
+			return newError("argument 2 must not be greater than argument 3")
+		}
+
+		switch {
+		case value < lo:
+			return args[1]
+		case value > hi:
+			return args[2]
+		default:
+			return args[0]
+		}
+	}},
+	// The result is never negative, and gcd(0, 0) is 0.
+	"gcd": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.INT_OBJ, object.INT_OBJ); err != nil {
+			return err
+		}
+		a, b := intArg(args, 0), intArg(args, 1)
+		for b != 0 {
+			a, b = b, a%b
+		}
+		if a == math.MinInt64 {
+			return newError("integer overflow")
+		}
+		return &object.Integer{Value: abs(a)}
+	}},
+	// Both bounds are inclusive.
+	"random_int": {caps: CapRandom, fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.INT_OBJ, object.INT_OBJ); err != nil {
+			return err
+		}
+		lo, hi := intArg(args, 0), intArg(args, 1)
+		if lo > hi {
+			return newError("argument 1 must not be greater than argument 2")
+		}
+
+		n := uint64(hi - lo)
+		if n == math.MaxUint64 {
+			return &object.Integer{Value: int64(in.rand.Uint64())}
+		}
+		return &object.Integer{Value: lo + int64(in.randUint64n(n+1))}
+	}},
+	"random_choice": {caps: CapRandom, fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.ARRAY_OBJ); err != nil {
+			return err
+		}
+		elements := args[0].(*object.Array).Elements
+		if len(elements) == 0 {
+			return newError("argument must not be empty")
+		}
+		return elements[in.rand.Intn(len(elements))]
+	}},
+	"shuffle": {caps: CapRandom, fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.ARRAY_OBJ); err != nil {
+			return err
+		}
+		arr := args[0].(*object.Array)
+
+		elements := make([]object.Object, len(arr.Elements))
+		copy(elements, arr.Elements)
+		in.rand.Shuffle(len(elements), func(i, j int) {
+			elements[i], elements[j] = elements[j], elements[i]
+		})
+		return in.newArray(elements)
+	}},
+}
+
+func intArg(args []object.Object, i int) int64 {
+	return args[i].(*object.Integer).Value
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// multiply returns a * b, and whether it fits in an integer.
+func multiply(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	if c/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return c, false
+	}
+	return c, true
+}
+
+// extremum returns the integer for which better holds against every other
+// one. Integers are given either as arguments or as a single array.
+func extremum(args []object.Object, better func(a, b int64) bool) object.Object {
+	if len(args) == 1 {
+		if arr, ok := args[0].(*object.Array); ok {
+			if len(arr.Elements) == 0 {
+				return newError("argument must not be empty")
+			}
+			args = arr.Elements
+		}
+	}
+	if len(args) == 0 {
+		return newError("wrong number of arguments, expected at least 1")
+	}
+
+	var result *object.Integer
+	for i, arg := range args {
+		n, ok := arg.(*object.Integer)
+		if !ok {
+			return newError("argument %d must be of type 'INTEGER'", i+1)
+		}
+		if result == nil || better(n.Value, result.Value) {
+			result = n
+		}
+	}
+	return result
+}
+
+// divisionArgs validates the arguments of `floor` and `ceil`, where the
+// divisor defaults to 1.
+func divisionArgs(args []object.Object) (int64, int64, *object.Error) {
+	switch len(args) {
+	case 1:
+		if err := checkArgs(args, object.INT_OBJ); err != nil {
+			return 0, 0, err
+		}
+		return intArg(args, 0), 1, nil
+	case 2:
+		if err := checkArgs(args, object.INT_OBJ, object.INT_OBJ); err != nil {
+			return 0, 0, err
+		}
+		a, b := intArg(args, 0), intArg(args, 1)
+		if b == 0 {
+			return 0, 0, newError("division by zero")
+		}
+		if a == math.MinInt64 && b == -1 {
+			return 0, 0, newError("integer overflow")
+		}
+		return a, b, nil
+	default:
+		return 0, 0, newError("wrong number of arguments, expected 1 or 2")
+	}
+}
+
+// randUint64n returns a uniformly distributed number in [0, n), which unlike
+// rand.Int63n accepts the whole range of differences between two integers.
+func (in *Interpreter) randUint64n(n uint64) uint64 {
+	limit := math.MaxUint64 - math.MaxUint64%n
+	for {
+		if v := in.rand.Uint64(); v < limit {
+			return v % n
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package eval
+
+import (
+	"testing"
+
+	"monkey/object"
+)
+
+func TestMathBuiltins(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected interface{}
+	}{
+		{`abs(-5)`, 5},
+		{`abs(5)`, 5},
+		{`abs(-9223372036854775807 - 1)`, errorMessage("integer overflow")},
+		{`min(3, 1, 2)`, 1},
+		{`max(3, 1, 2)`, 3},
+		{`min([4, -2, 7])`, -2},
+		{`max([4])`, 4},
+		{`max([])`, errorMessage("argument must not be empty")},
+		{`min()`, errorMessage("wrong number of arguments, expected at least 1")},
+		{`min(1, "a")`, errorMessage("argument 2 must be of type 'INTEGER'")},
+		{`pow(2, 10)`, 1024},
+		{`pow(-3, 3)`, -27},
+		{`pow(5, 0)`, 1},
+		{`pow(1, 9223372036854775807)`, 1},
+		{`pow(2, 62)`, 4611686018427387904},
+		{`pow(2, 63)`, errorMessage("integer overflow")},
+		{`pow(-2, 63)`, -9223372036854775807 - 1},
+		{`pow(2, -1)`, errorMessage("argument 2 must not be negative")},
+		{`sqrt(16)`, 4},
+		{`sqrt(17)`, 4},
+		{`sqrt(0)`, 0},
+		{`sqrt(9223372036854775807)`, 3037000499},
+		{`sqrt(-1)`, errorMessage("argument must not be negative")},
+		{`floor(7)`, 7},
+		{`floor(7, 2)`, 3},
+		{`floor(-7, 2)`, -4},
+		{`floor(7, -2)`, -4},
+		{`ceil(7, 2)`, 4},
+		{`ceil(-7, 2)`, -3},
+		{`ceil(6, 2)`, 3},
+		{`ceil(1, 0)`, errorMessage("division by zero")},
+		{`floor(1, 2, 3)`, errorMessage("wrong number of arguments, expected 1 or 2")},
+		{`clamp(5, 0, 10)`, 5},
+		{`clamp(-5, 0, 10)`, 0},
+		{`clamp(15, 0, 10)`, 10},
+		{`clamp(5, 10, 0)`, errorMessage("argument 2 must not be greater than argument 3")},
+		{`gcd(12, 18)`, 6},
+		{`gcd(-12, 18)`, 6},
+		{`gcd(7, 0)`, 7},
+		{`gcd(0, 0)`, 0},
+	} {
+		testObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestRandomBuiltins(t *testing.T) {
+	const input = `[random_int(1, 100), random_choice(["a", "b", "c"]), shuffle([1, 2, 3, 4, 5])]`
+
+	run := func(seed int64) string {
+		in := New(WithCapabilities(CapRandom), WithSeed(seed))
+		return testEvalWith(in, input).Inspect()
+	}
+
+	first := run(42)
+	if second := run(42); first != second {
+		t.Errorf("same seed gave different results: %s and %s", first, second)
+	}
+
+	in := New(WithCapabilities(CapRandom), WithSeed(1))
+	for i := 0; i < 100; i++ {
+		n, ok := testEvalWith(in, `random_int(-3, 3)`).(*object.Integer)
+		if !ok || n.Value < -3 || n.Value > 3 {
+			t.Fatalf("random_int(-3, 3) gave %v", n)
+		}
+	}
+	testObject(t, testEvalWith(in, `random_int(5, 5)`), 5)
+	testObject(t, testEvalWith(in, `sort(shuffle([3, 1, 2]))`), []interface{}{1, 2, 3})
+	testObject(t, testEvalWith(in, `random_int(2, 1)`),
+		errorMessage("argument 1 must not be greater than argument 2"))
+	testObject(t, testEvalWith(in, `random_choice([])`),
+		errorMessage("argument must not be empty"))
+
+	testObject(t, testEval(`random_int(1, 6)`),
+		errorMessage("permission denied: `random_int` requires random capability"))
+}
@@ -0,0 +1,157 @@
+package eval
+
+import (
+	"regexp"
+
+	"monkey/object"
+)
+
+// Compiled patterns are cached until there are this many of them, at which
+// point the cache is emptied.
+const maxCachedRegexps = 64
+
+func init() {
+	registerBuiltins(regexBuiltins)
+}
+
+// Patterns use the syntax of Go's regexp package. A match is an array holding
+// the matched text followed by every capture group, or null for groups that
+// did not participate. When the pattern has named groups, a match is instead
+// a hash where groups can be looked up both by index and by name.
+var regexBuiltins = map[string]builtin{
+	"regex_match": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+			return err
+		}
+		re, err := in.compileRegexp(stringArg(args, 1))
+		if err != nil {
+			return err
+		}
+		s := stringArg(args, 0)
+
+		loc := re.FindStringSubmatchIndex(s)
+		if loc == nil {
+			return NULL
+		}
+		return in.newMatch(re, s, loc)
+	}},
+	"regex_find_all": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+			return err
+		}
+		re, err := in.compileRegexp(stringArg(args, 1))
+		if err != nil {
+			return err
+		}
+		s := stringArg(args, 0)
+
+		locs := re.FindAllStringSubmatchIndex(s, -1)
+		matches := make([]object.Object, len(locs))
+		for i, loc := range locs {
+			matches[i] = in.newMatch(re, s, loc)
+			if isError(matches[i]) {
+				return matches[i]
+			}
+		}
+		return in.newArray(matches)
+	}},
+	// The replacement may refer to groups as in Go, with `$1` or `${name}`.
+	"regex_replace": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		err := checkArgs(args, object.STRING_OBJ, object.STRING_OBJ, object.STRING_OBJ)
+		if err != nil {
+			return err
+		}
+		re, err := in.compileRegexp(stringArg(args, 1))
+		if err != nil {
+			return err
+		}
+		s, repl := stringArg(args, 0), stringArg(args, 2)
+
+		// Expand the replacement one match at a time, so that the limits
+		// are checked against the whole result before it grows any further.
+		result := []byte{}
+		last := 0
+		for _, loc := range re.FindAllStringSubmatchIndex(s, -1) {
+			result = append(result, s[last:loc[0]]...)
+			result = re.ExpandString(result, repl, s, loc)
+			if err := in.fits(int64(len(result))); err != nil {
+				return err
+			}
+			last = loc[1]
+		}
+		result = append(result, s[last:]...)
+		if err := in.alloc(int64(len(result))); err != nil {
+			return err
+		}
+
+		return &object.String{Value: string(result)}
+	}},
+	"regex_split": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+			return err
+		}
+		re, err := in.compileRegexp(stringArg(args, 1))
+		if err != nil {
+			return err
+		}
+		return in.newStringArray(re.Split(stringArg(args, 0), -1))
+	}},
+}
+
+func (in *Interpreter) compileRegexp(pattern string) (*regexp.Regexp, *object.Error) {
+	if re, ok := in.regexps[pattern]; ok {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, newError("invalid regular expression: %s", err)
+	}
+	if in.regexps == nil || len(in.regexps) >= maxCachedRegexps {
+		in.regexps = make(map[string]*regexp.Regexp)
+	}
+	in.regexps[pattern] = re
+
+	return re, nil
+}
+
+// newMatch builds the value describing a match of re in s, where loc holds
+// the start and end of every group as returned by FindStringSubmatchIndex.
+func (in *Interpreter) newMatch(re *regexp.Regexp, s string, loc []int) object.Object {
+	groups := make([]object.Object, len(loc)/2)
+	for i := range groups {
+		if loc[2*i] < 0 {
+			groups[i] = NULL
+			continue
+		}
+		groups[i] = in.newString(s[loc[2*i]:loc[2*i+1]])
+		if isError(groups[i]) {
+			return groups[i]
+		}
+	}
+
+	named := false
+	for _, name := range re.SubexpNames() {
+		named = named || name != ""
+	}
+	if !named {
+		return in.newArray(groups)
+	}
+
+	hash, err := in.newHash(len(groups) + re.NumSubexp())
+	if err != nil {
+		return err
+	}
+	for i, group := range groups {
+		key := &object.Integer{Value: int64(i)}
+		hash.Set(key.HashKey(), object.HashPair{Key: key, Value: group})
+	}
+	for i, name := range re.SubexpNames() {
+		if name == "" {
+			continue
+		}
+		key := &object.String{Value: name}
+		hash.Set(key.HashKey(), object.HashPair{Key: key, Value: groups[i]})
+	}
+	return hash
+}
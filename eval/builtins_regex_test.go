@@ -0,0 +1,58 @@
+package eval
+
+import "testing"
+
+func TestRegexBuiltins(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected interface{}
+	}{
+		{`regex_match("abc123", "[0-9]+")`, []interface{}{"123"}},
+		{`regex_match("abc", "[0-9]+")`, nil},
+		{`regex_match("2024-05", "([0-9]+)-([0-9]+)")`, []interface{}{"2024-05", "2024", "05"}},
+		{`regex_match("b", "(a)?b")`, []interface{}{"b", nil}},
+		{`let m = regex_match("2024-05", "(?P<year>[0-9]+)-([0-9]+)"); [m["year"], m[0], m[2]]`,
+			[]interface{}{"2024", "2024-05", "05"}},
+		{`regex_match("a", "(")`, errorMessage("invalid regular expression: error parsing regexp: missing closing ): `(`")},
+		{`regex_match(1, "a")`, errorMessage("argument 1 must be of type 'STRING'")},
+		{`regex_find_all("a1 b22 c333", "[0-9]+")`,
+			[]interface{}{[]interface{}{"1"}, []interface{}{"22"}, []interface{}{"333"}}},
+		{`regex_find_all("a=1, b=2", "(\w)=(\d)")`,
+			[]interface{}{[]interface{}{"a=1", "a", "1"}, []interface{}{"b=2", "b", "2"}}},
+		{`regex_find_all("abc", "[0-9]")`, []interface{}{}},
+		{`regex_replace("a1b22", "[0-9]+", "#")`, "a#b#"},
+		{`regex_replace("john smith", "(\w+) (\w+)", "$2, $1")`, "smith, john"},
+		{`regex_replace("x=1", "(?P<k>\w)=(?P<v>\w)", "${v}=${k}")`, "1=x"},
+		{`regex_replace("abc", "z", "y")`, "abc"},
+		{`regex_split("a, b,c ,d", "\s*,\s*")`, []interface{}{"a", "b", "c", "d"}},
+		{`regex_split("", ",")`, []interface{}{""}},
+	} {
+		testObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestRegexCache(t *testing.T) {
+	in := New()
+	testEvalWith(in, `regex_match("a", "a"); regex_match("b", "a"); regex_split("a", "b")`)
+
+	if len(in.regexps) != 2 {
+		t.Errorf("expected 2 cached patterns, got %d", len(in.regexps))
+	}
+}
+
+func TestRegexReplaceLimits(t *testing.T) {
+	in := New(WithLimits(Limits{MaxAllocated: 100}))
+	result := testEvalWith(in, `regex_replace("aaaaaaaaaa", "a", "0123456789")`)
+
+	testObject(t, result, errorMessage("resource limit exceeded: allocated more than 100 bytes"))
+}
+
+func TestRegexReplaceValueSize(t *testing.T) {
+	in := New(WithLimits(Limits{MaxValueSize: 100}))
+	result := testEvalWith(in, `regex_replace(repeat("a", 20), "a", "aaaaaa")`)
+
+	testObject(t, result, errorMessage("resource limit exceeded: value of 102 bytes exceeds 100"))
+	if in.Allocated() != 28 {
+		t.Errorf("expected only the arguments to be accounted for, got %d bytes", in.Allocated())
+	}
+}
@@ -0,0 +1,203 @@
+package eval
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"monkey/object"
+)
+
+func init() {
+	registerBuiltins(stringBuiltins)
+}
+
+var stringBuiltins = map[string]builtin{
+	"split": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+			return err
+		}
+		parts := strings.Split(stringArg(args, 0), stringArg(args, 1))
+
+		return in.newStringArray(parts)
+	}},
+	"join": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.ARRAY_OBJ, object.STRING_OBJ); err != nil {
+			return err
+		}
+		elements := args[0].(*object.Array).Elements
+
+		parts := make([]string, len(elements))
+		for i, e := range elements {
+			str, ok := e.(*object.String)
+			if !ok {
+				return newError("argument 1 must only contain elements of type 'STRING'")
+			}
+			parts[i] = str.Value
+		}
+		return in.newString(strings.Join(parts, stringArg(args, 1)))
+	}},
+	"trim": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.STRING_OBJ); err != nil {
+			return err
+		}
+		return in.newString(strings.TrimSpace(stringArg(args, 0)))
+	}},
+	"upper": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.STRING_OBJ); err != nil {
+			return err
+		}
+		return in.newString(strings.ToUpper(stringArg(args, 0)))
+	}},
+	"lower": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.STRING_OBJ); err != nil {
+			return err
+		}
+		return in.newString(strings.ToLower(stringArg(args, 0)))
+	}},
+	"contains": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+			return err
+		}
+		return booleanObject(strings.Contains(stringArg(args, 0), stringArg(args, 1)))
+	}},
+	"starts_with": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+			return err
+		}
+		return booleanObject(strings.HasPrefix(stringArg(args, 0), stringArg(args, 1)))
+	}},
+	"ends_with": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+			return err
+		}
+		return booleanObject(strings.HasSuffix(stringArg(args, 0), stringArg(args, 1)))
+	}},
+	"replace": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		err := checkArgs(args, object.STRING_OBJ, object.STRING_OBJ, object.STRING_OBJ)
+		if err != nil {
+			return err
+		}
+		s, from, to := stringArg(args, 0), stringArg(args, 1), stringArg(args, 2)
+
+		// Check the limits before replacing, as the result may be much
+		// larger than the original string.
+		n := int64(strings.Count(s, from))
+		if err := in.alloc(int64(len(s)) + n*int64(len(to)-len(from))); err != nil {
+			return err
+		}
+		return &object.String{Value: strings.ReplaceAll(s, from, to)}
+	}},
+	"index_of": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+			return err
+		}
+		index := strings.Index(stringArg(args, 0), stringArg(args, 1))
+
+		return &object.Integer{Value: int64(index)}
+	}},
+	"repeat": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.STRING_OBJ, object.INT_OBJ); err != nil {
+			return err
+		}
+		s, count := stringArg(args, 0), args[1].(*object.Integer).Value
+		if count < 0 {
+			return newError("argument 2 must not be negative")
+		}
+		if len(s) > 0 && count > math.MaxInt32/int64(len(s)) {
+			return newError("argument 2 is too large")
+		}
+
+		if err := in.alloc(int64(len(s)) * count); err != nil {
+			return err
+		}
+		return &object.String{Value: strings.Repeat(s, int(count))}
+	}},
+	"pad_left": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		return pad(in, args, true)
+	}},
+	"pad_right": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		return pad(in, args, false)
+	}},
+	"chars": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.STRING_OBJ); err != nil {
+			return err
+		}
+		s := stringArg(args, 0)
+
+		chars := make([]string, 0, len(s))
+		for _, r := range s {
+			chars = append(chars, string(r))
+		}
+		return in.newStringArray(chars)
+	}},
+}
+
+// pad implements `pad_left` and `pad_right`, which extend a string to the
+// given length with spaces or with an optional padding string.
+func pad(in *Interpreter, args []object.Object, left bool) object.Object {
+	padding := " "
+
+	if len(args) == 3 {
+		if args[2].Type() != object.STRING_OBJ {
+			return newError("argument 3 must be of type 'STRING'")
+		}
+		padding = stringArg(args, 2)
+		if padding == "" {
+			return newError("argument 3 must not be empty")
+		}
+		args = args[:2]
+	}
+	if err := checkArgs(args, object.STRING_OBJ, object.INT_OBJ); err != nil {
+		if len(args) != 2 {
+			return newError("wrong number of arguments, expected 2 or 3")
+		}
+		return err
+	}
+	s, length := stringArg(args, 0), args[1].(*object.Integer).Value
+
+	// Lengths are counted in characters, so that padding with multibyte
+	// characters never splits one.
+	missing := length - int64(utf8.RuneCountInString(s))
+	if missing <= 0 {
+		return args[0]
+	}
+	chars := []rune(padding)
+	count := missing / int64(len(chars))
+	if count > math.MaxInt32/int64(len(padding)) {
+		return newError("argument 2 is too large")
+	}
+	rest := string(chars[:missing%int64(len(chars))])
+
+	if err := in.alloc(int64(len(s)) + count*int64(len(padding)) + int64(len(rest))); err != nil {
+		return err
+	}
+
+	fill := strings.Repeat(padding, int(count)) + rest
+	if left {
+		return &object.String{Value: fill + s}
+	}
+	return &object.String{Value: s + fill}
+}
+
+func stringArg(args []object.Object, i int) string {
+	return args[i].(*object.String).Value
+}
+
+// newStringArray returns an array holding a string object for each of
+// values.
+func (in *Interpreter) newStringArray(values []string) object.Object {
+	size := int64(len(values)) * arrayElementSize
+	for _, v := range values {
+		size += int64(len(v))
+	}
+	if err := in.alloc(size); err != nil {
+		return err
+	}
+
+	elements := make([]object.Object, len(values))
+	for i, v := range values {
+		elements[i] = &object.String{Value: v}
+	}
+	return &object.Array{Elements: elements}
+}
@@ -0,0 +1,70 @@
+package eval
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStringBuiltins(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected interface{}
+	}{
+		{`split("a,b,,c", ",")`, []interface{}{"a", "b", "", "c"}},
+		{`split("abc", "")`, []interface{}{"a", "b", "c"}},
+		{`split("abc", 1)`, errorMessage("argument 2 must be of type 'STRING'")},
+		{`join(["a", "b", "c"], "-")`, "a-b-c"},
+		{`join([], "-")`, ""},
+		{`join(["a", 1], "-")`, errorMessage("argument 1 must only contain elements of type 'STRING'")},
+		{`join("abc")`, errorMessage("wrong number of arguments, expected 2")},
+		{"trim(\"  a b \n\")", "a b"},
+		{`upper("MonKey")`, "MONKEY"},
+		{`lower("MonKey")`, "monkey"},
+		{`lower(1)`, errorMessage("argument must be of type 'STRING'")},
+		{`contains("monkey", "key")`, true},
+		{`contains("monkey", "ape")`, false},
+		{`starts_with("monkey", "mon")`, true},
+		{`starts_with("monkey", "key")`, false},
+		{`ends_with("monkey", "key")`, true},
+		{`ends_with("monkey", "mon")`, false},
+		{`replace("a-b-c", "-", "+")`, "a+b+c"},
+		{`replace("abc", "x", "y")`, "abc"},
+		{`index_of("monkey", "key")`, 3},
+		{`index_of("monkey", "ape")`, -1},
+		{`repeat("ab", 3)`, "ababab"},
+		{`repeat("ab", 0)`, ""},
+		{`repeat("ab", -1)`, errorMessage("argument 2 must not be negative")},
+		{`repeat("ab", 9223372036854775807)`, errorMessage("argument 2 is too large")},
+		{`pad_left("7", 3)`, "  7"},
+		{`pad_left("7", 3, "0")`, "007"},
+		{`pad_right("ab", 7, "xy")`, "abxyxyx"},
+		{`pad_right("abc", 2)`, "abc"},
+		{`pad_left("a", 4, "é")`, "éééa"},
+		{`pad_right("né", 5, "àb")`, "néàbà"},
+		{`pad_left("a", 9223372036854775807)`, errorMessage("argument 2 is too large")},
+		{`pad_right("a", 9223372036854775807, "xyz")`, errorMessage("argument 2 is too large")},
+		{`pad_right("abc", 5, "")`, errorMessage("argument 3 must not be empty")},
+		{`pad_left("abc")`, errorMessage("wrong number of arguments, expected 2 or 3")},
+		{`pad_left(1, 2)`, errorMessage("argument 1 must be of type 'STRING'")},
+		{`chars("héllo")`, []interface{}{"h", "é", "l", "l", "o"}},
+		{`chars("")`, []interface{}{}},
+	} {
+		testObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestStringBuiltinLimits(t *testing.T) {
+	in := New(WithLimits(Limits{MaxValueSize: 100}))
+
+	for _, tt := range []struct {
+		input    string
+		expected interface{}
+	}{
+		{`repeat("ab", 50)`, strings.Repeat("ab", 50)},
+		{`repeat("ab", 51)`, errorMessage("resource limit exceeded: value of 102 bytes exceeds 100")},
+		{`replace(repeat("a", 20), "a", "aaaaaa")`, errorMessage("resource limit exceeded: value of 120 bytes exceeds 100")},
+		{`pad_left("", 101)`, errorMessage("resource limit exceeded: value of 101 bytes exceeds 100")},
+	} {
+		testObject(t, testEvalWith(in, tt.input), tt.expected)
+	}
+}
@@ -0,0 +1,130 @@
+package eval
+
+import (
+	"math"
+	"time"
+
+	"monkey/object"
+)
+
+func init() {
+	registerBuiltins(timeBuiltins)
+}
+
+// Layouts that may be given by name to `format_time` and `parse_time`.
+var timeLayouts = map[string]string{
+	"rfc3339":  time.RFC3339,
+	"rfc1123":  time.RFC1123,
+	"datetime": "2006-01-02 15:04:05",
+	"date":     "2006-01-02",
+	"time":     "15:04:05",
+}
+
+// Times are integers counting milliseconds since the Unix epoch, and
+// durations are integers counting milliseconds, so they can be combined with
+// the usual arithmetic operators. Layouts follow Go's time package, and times
+// are formatted and parsed in UTC unless the layout includes a zone.
+var timeBuiltins = map[string]builtin{
+	"now": {caps: CapTime, fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args); err != nil {
+			return err
+		}
+		return &object.Integer{Value: in.clock.Now().UnixMilli()}
+	}},
+	"sleep": {caps: CapTime, fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.INT_OBJ); err != nil {
+			return err
+		}
+		ms := intArg(args, 0)
+		if ms < 0 {
+			return newError("argument must not be negative")
+		}
+		d, err := milliseconds(ms)
+		if err != nil {
+			return err
+		}
+		in.clock.Sleep(d)
+
+		return NULL
+	}},
+	"format_time": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		layout := time.RFC3339
+		switch len(args) {
+		case 1:
+			if err := checkArgs(args, object.INT_OBJ); err != nil {
+				return err
+			}
+		case 2:
+			if err := checkArgs(args, object.INT_OBJ, object.STRING_OBJ); err != nil {
+				return err
+			}
+			layout = timeLayout(stringArg(args, 1))
+		default:
+			return newError("wrong number of arguments, expected 1 or 2")
+		}
+		t := time.UnixMilli(intArg(args, 0)).UTC()
+
+		return in.newString(t.Format(layout))
+	}},
+	"parse_time": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		layout := time.RFC3339
+		switch len(args) {
+		case 1:
+			if err := checkArgs(args, object.STRING_OBJ); err != nil {
+				return err
+			}
+		case 2:
+			if err := checkArgs(args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+				return err
+			}
+			layout = timeLayout(stringArg(args, 1))
+		default:
+			return newError("wrong number of arguments, expected 1 or 2")
+		}
+
+		t, err := time.Parse(layout, stringArg(args, 0))
+		if err != nil {
+			return newError("could not parse time: %s", err)
+		}
+		return &object.Integer{Value: t.UnixMilli()}
+	}},
+	// `duration` parses strings such as "1h30m" into milliseconds, and
+	// `format_duration` does the opposite.
+	"duration": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.STRING_OBJ); err != nil {
+			return err
+		}
+		d, err := time.ParseDuration(stringArg(args, 0))
+		if err != nil {
+			return newError("could not parse duration: %s", err)
+		}
+		return &object.Integer{Value: d.Milliseconds()}
+	}},
+	"format_duration": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if err := checkArgs(args, object.INT_OBJ); err != nil {
+			return err
+		}
+		d, err := milliseconds(intArg(args, 0))
+		if err != nil {
+			return err
+		}
+		return in.newString(d.String())
+	}},
+}
+
+func timeLayout(name string) string {
+	if layout, ok := timeLayouts[name]; ok {
+		return layout
+	}
+	return name
+}
+
+// milliseconds returns the duration of ms milliseconds, which must not be
+// longer than a time.Duration can hold.
+func milliseconds(ms int64) (time.Duration, *object.Error) {
+	if ms > math.MaxInt64/int64(time.Millisecond) ||
+		ms < math.MinInt64/int64(time.Millisecond) {
+		return 0, newError("argument is out of range")
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
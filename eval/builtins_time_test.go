@@ -0,0 +1,55 @@
+package eval
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeBuiltins(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected interface{}
+	}{
+		{`format_time(0)`, "1970-01-01T00:00:00Z"},
+		{`format_time(1700000000000, "datetime")`, "2023-11-14 22:13:20"},
+		{`format_time(1700000000000, "Jan 2, 2006")`, "Nov 14, 2023"},
+		{`parse_time("2023-11-14T22:13:20Z")`, 1700000000000},
+		{`parse_time("2023-11-14", "date")`, 1699920000000},
+		{`parse_time("2023-11-14T23:13:20+01:00")`, 1700000000000},
+		{`parse_time("tomorrow")`, errorMessage(`could not parse time: parsing time "tomorrow" as "2006-01-02T15:04:05Z07:00": cannot parse "tomorrow" as "2006"`)},
+		{`format_time(parse_time("2024-02-28", "date") + duration("24h"), "date")`, "2024-02-29"},
+		{`duration("1h30m")`, 5400000},
+		{`duration("250ms")`, 250},
+		{`duration("soon")`, errorMessage(`could not parse duration: time: invalid duration "soon"`)},
+		{`format_duration(5400000)`, "1h30m0s"},
+		{`format_duration(9223372036854775807)`, errorMessage("argument is out of range")},
+		{`format_duration(-9223372036854775807)`, errorMessage("argument is out of range")},
+		{`format_duration(9223372036854)`, "2562047h47m16.854s"},
+		{`now()`, errorMessage("permission denied: `now` requires time capability")},
+	} {
+		testObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestInjectedClock(t *testing.T) {
+	clock := NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	in := New(WithCapabilities(CapTime), WithClock(clock))
+
+	testObject(t, testEvalWith(in, `format_time(now())`), "2024-01-01T00:00:00Z")
+
+	clock.Advance(90 * time.Minute)
+	testObject(t, testEvalWith(in, `format_time(now(), "time")`), "01:30:00")
+
+	input := `let start = now(); sleep(duration("2h")); now() - start`
+	testObject(t, testEvalWith(in, input), 7200000)
+	if got := clock.Now(); !got.Equal(time.Date(2024, 1, 1, 3, 30, 0, 0, time.UTC)) {
+		t.Errorf("sleep did not advance the clock, now is %s", got)
+	}
+
+	testObject(t, testEvalWith(in, `sleep(-1)`), errorMessage("argument must not be negative"))
+	testObject(t, testEvalWith(in, `sleep(9223372036854775807)`),
+		errorMessage("argument is out of range"))
+	if got := clock.Now(); !got.Equal(time.Date(2024, 1, 1, 3, 30, 0, 0, time.UTC)) {
+		t.Errorf("sleep with an argument out of range moved the clock to %s", got)
+	}
+}
@@ -0,0 +1,88 @@
+package eval
+
+import (
+	"strconv"
+	"strings"
+
+	"monkey/format"
+	"monkey/object"
+)
+
+func init() {
+	registerBuiltins(typeBuiltins)
+}
+
+var typeBuiltins = map[string]builtin{
+	"type": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("wrong number of arguments, expected 1")
+		}
+		return in.newString(string(args[0].Type()))
+	}},
+	"str": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("wrong number of arguments, expected 1")
+		}
+		switch arg := args[0].(type) {
+		case *object.String:
+			return arg
+		case *object.Quote:
+			return in.newString(format.Node(arg.Node))
+		}
+		return in.newString(args[0].Inspect())
+	}},
+	"int": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("wrong number of arguments, expected 1")
+		}
+
+		switch arg := args[0].(type) {
+		case *object.Integer:
+			return arg
+		case *object.Boolean:
+			if arg.Value {
+				return &object.Integer{Value: 1}
+			}
+			return &object.Integer{Value: 0}
+		case *object.String:
+			value, err := strconv.ParseInt(strings.TrimSpace(arg.Value), 10, 64)
+			if err != nil {
+				return newError("could not parse %q as integer", arg.Value)
+			}
+			return &object.Integer{Value: value}
+		default:
+			return newError("cannot convert %s to INTEGER", arg.Type())
+		}
+	}},
+	// Values are converted following the same rules as conditions, so only
+	// false and null are false.
+	"bool": {fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("wrong number of arguments, expected 1")
+		}
+		return booleanObject(isTrue(args[0]))
+	}},
+	"is_int":    typePredicate(object.INT_OBJ),
+	"is_bool":   typePredicate(object.BOOL_OBJ),
+	"is_string": typePredicate(object.STRING_OBJ),
+	"is_null":   typePredicate(object.NULL_OBJ),
+	"is_array":  typePredicate(object.ARRAY_OBJ),
+	"is_hash":   typePredicate(object.HASH_OBJ),
+	"is_fn":     typePredicate(object.FUNCTION_OBJ, object.BUILTIN_OBJ),
+}
+
+// typePredicate returns a builtin reporting whether its argument is of any
+// of the given types.
+func typePredicate(types ...object.ObjectType) builtin {
+	return builtin{fn: func(in *Interpreter, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("wrong number of arguments, expected 1")
+		}
+		for _, t := range types {
+			if args[0].Type() == t {
+				return TRUE
+			}
+		}
+		return FALSE
+	}}
+}
@@ -0,0 +1,48 @@
+package eval
+
+import "testing"
+
+func TestTypeBuiltins(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected interface{}
+	}{
+		{`type(1)`, "INTEGER"},
+		{`type("a")`, "STRING"},
+		{`type(true)`, "BOOLEAN"},
+		{`type(if (false) { 1 })`, "NULL"},
+		{`type([])`, "ARRAY"},
+		{`type({})`, "HASH"},
+		{`type(fn() {})`, "FUNCTION"},
+		{`type(len)`, "BUILTIN"},
+		{`type()`, errorMessage("wrong number of arguments, expected 1")},
+		{`str(12)`, "12"},
+		{`str("a")`, "a"},
+		{`str([1, "a", true])`, "[1, a, true]"},
+		{`str({"a": 1})`, "{a: 1}"},
+		{`int(5)`, 5},
+		{`int(" -42 ")`, -42},
+		{`int(true)`, 1},
+		{`int(false)`, 0},
+		{`int("4x")`, errorMessage(`could not parse "4x" as integer`)},
+		{`int("99999999999999999999")`, errorMessage(`could not parse "99999999999999999999" as integer`)},
+		{`int([])`, errorMessage("cannot convert ARRAY to INTEGER")},
+		{`bool(0)`, true},
+		{`bool("")`, true},
+		{`bool(false)`, false},
+		{`bool(if (false) { 1 })`, false},
+		{`is_int(1)`, true},
+		{`is_int("1")`, false},
+		{`is_string("1")`, true},
+		{`is_bool(false)`, true},
+		{`is_null(if (false) { 1 })`, true},
+		{`is_array([])`, true},
+		{`is_array({})`, false},
+		{`is_hash({})`, true},
+		{`is_fn(fn(x) { x })`, true},
+		{`is_fn(len)`, true},
+		{`is_fn(1)`, false},
+	} {
+		testObject(t, testEval(tt.input), tt.expected)
+	}
+}
@@ -0,0 +1,81 @@
+package eval
+
+import (
+	"strings"
+
+	"monkey/object"
+)
+
+// Capability is a set of privileges that builtins may require before being
+// allowed to touch anything outside of the interpreter.
+type Capability uint
+
+const (
+	CapFileRead Capability = 1 << iota
+	CapFileWrite
+	CapTime
+	CapEnv
+	CapRandom
+
+	NoCapabilities  Capability = 0
+	AllCapabilities            = CapFileRead | CapFileWrite | CapTime | CapEnv | CapRandom
+)
+
+var capabilityNames = []struct {
+	cap  Capability
+	name string
+}{
+	{CapFileRead, "file read"},
+	{CapFileWrite, "file write"},
+	{CapTime, "time"},
+	{CapEnv, "environment"},
+	{CapRandom, "random"},
+}
+
+func (c Capability) String() string {
+	names := []string{}
+	for _, cn := range capabilityNames {
+		if c&cn.cap != 0 {
+			names = append(names, cn.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
+
+// WithCapabilities grants the interpreter the given capabilities. Without
+// it, only builtins that require no capabilities may be called.
+func WithCapabilities(caps Capability) Option {
+	return func(in *Interpreter) {
+		in.granted = caps
+	}
+}
+
+// HideForbidden makes builtins whose capabilities were not granted behave as
+// undefined identifiers, instead of failing when called.
+func HideForbidden() Option {
+	return func(in *Interpreter) {
+		in.hideForbidden = true
+	}
+}
+
+// Granted reports whether every capability in caps was granted.
+func (in *Interpreter) Granted(caps Capability) bool {
+	return in.granted&caps == caps
+}
+
+func (in *Interpreter) guard(name string, b builtin) *object.Builtin {
+	if in.Granted(b.caps) {
+		return in.bind(b.fn)
+	}
+	missing := b.caps &^ in.granted
+
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			return newError("permission denied: `%s` requires %s capability",
+				name, missing)
+		},
+	}
+}
@@ -0,0 +1,43 @@
+package eval
+
+import "time"
+
+// Clock is the source of time for builtins such as `now` and `sleep`.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+// WithClock makes time builtins use c instead of the system clock.
+func WithClock(c Clock) Option {
+	return func(in *Interpreter) {
+		in.clock = c
+	}
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time        { return time.Now() }
+func (systemClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// ManualClock is a clock that only moves when told to, so that scripts
+// depending on time can be run deterministically. Sleeping advances it
+// immediately.
+type ManualClock struct {
+	now time.Time
+}
+
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+func (c *ManualClock) Now() time.Time        { return c.now }
+func (c *ManualClock) Sleep(d time.Duration) { c.Advance(d) }
+
+func (c *ManualClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func (c *ManualClock) Set(now time.Time) {
+	c.now = now
+}
@@ -13,98 +13,129 @@ var (
 	NULL  = &object.Null{}
 )
 
+// Eval evaluates node with a new interpreter using the default options. Use
+// an Interpreter to set limits or capabilities, or to share state between
+// evaluations.
 func Eval(node ast.Node, env *object.Environment) object.Object {
+	return New().Eval(node, env)
+}
+
+func (in *Interpreter) Eval(node ast.Node, env *object.Environment) object.Object {
 	switch node := node.(type) {
 	case *ast.Program:
-		return evalProgram(node.Statements, env)
+		if err := in.expandMacros(node, env); err != nil {
+			return err
+		}
+		resolve(node)
+		return in.evalProgram(node.Statements, env)
 	case *ast.BlockStatement:
-		return evalBlockStatement(node, env)
+		return in.evalBlockStatement(node, env)
 	case *ast.ReturnStatement:
-		val := Eval(node.ReturnValue, env)
+		val := in.evalTail(node.ReturnValue, env)
 		if isError(val) {
 			return val
 		}
+		if _, ok := val.(*object.ReturnValue); ok {
+			return val
+		}
 		return &object.ReturnValue{Value: val}
 	case *ast.ExpressionStatement:
-		return Eval(node.Expression, env)
+		return in.Eval(node.Expression, env)
 	case *ast.LetStatement:
-		val := Eval(node.Value, env)
+		val := in.Eval(node.Value, env)
 		if isError(val) {
 			return val
 		}
-		env.Insert(node.Name.Value, val)
+		env.Define(node.Name, val)
+	case *ast.ExportStatement:
+		return in.Eval(node.Statement, env)
+	case *ast.ImportExpression:
+		return in.importModule(node.Path.Value)
 	case *ast.Identifier:
-		return evalIdentifier(node, env)
+		return in.evalIdentifier(node, env)
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: node.Value}
 	case *ast.Boolean:
 		return booleanObject(node.Value)
 	case *ast.PrefixExpression:
-		right := Eval(node.Right, env)
+		right := in.Eval(node.Right, env)
 		if isError(right) {
 			return right
 		}
 		return evalPrefixExpression(node.Operator, right)
 	case *ast.InfixExpression:
-		left := Eval(node.Left, env)
+		left := in.Eval(node.Left, env)
 		if isError(left) {
 			return left
 		}
-		right := Eval(node.Right, env)
+		right := in.Eval(node.Right, env)
 		if isError(right) {
 			return right
 		}
-		return evalInfixExpression(node.Operator, left, right)
+		return in.evalInfixExpression(node.Operator, left, right)
 	case *ast.IfExpression:
-		return evalIfExpression(node, env)
+		return in.evalIfExpression(node, env)
 	case *ast.FunctionLiteral:
 		params := node.Parameters
 		body := node.Body
-		return &object.Function{Parameters: params, Env: env, Body: body}
+		return &object.Function{Parameters: params, Env: env, Body: body, Locals: node.Locals}
+	case *ast.MacroLiteral:
+		return &object.Macro{Parameters: node.Parameters, Env: env, Body: node.Body}
 	case *ast.CallExpression:
-		fn := Eval(node.Function, env)
+		if isIdentifier(node.Function, "quote") {
+			if len(node.Arguments) != 1 {
+				return newError("wrong number of arguments to quote, expected 1, got %d",
+					len(node.Arguments))
+			}
+			return in.quote(node.Arguments[0], env)
+		}
+		fn := in.Eval(node.Function, env)
 		if isError(fn) {
 			return fn
 		}
-		args := evalExpressions(node.Arguments, env)
+		args := in.evalExpressions(node.Arguments, env)
 		if len(args) == 1 && isError(args[0]) {
 			return args[0]
 		}
-		return applyFunction(fn, args)
+		return in.applyFunction(fn, args)
 	case *ast.StringLiteral:
-		return &object.String{Value: node.Value}
+		return in.newString(node.Value)
 	case *ast.ArrayLiteral:
-		elements := evalExpressions(node.Elements, env)
+		elements := in.evalExpressions(node.Elements, env)
 		if len(elements) == 1 && isError(elements[0]) {
 			return elements[0]
 		}
-		return &object.Array{Elements: elements}
+		return in.newArray(elements)
 	case *ast.IndexExpression:
-		left := Eval(node.Left, env)
+		left := in.Eval(node.Left, env)
 		if isError(left) {
 			return left
 		}
-		index := Eval(node.Index, env)
+		index := in.Eval(node.Index, env)
 		if isError(index) {
 			return index
 		}
 		return evalIndexExpression(left, index)
 	case *ast.HashLiteral:
-		return evalHashLiteral(node, env)
+		return in.evalHashLiteral(node, env)
 	}
 
 	return nil
 }
 
-func evalProgram(stmts []ast.Statement, env *object.Environment) object.Object {
+func (in *Interpreter) evalProgram(
+	stmts []ast.Statement,
+	env *object.Environment,
+) object.Object {
+
 	var result object.Object
 
 	for _, stmt := range stmts {
-		result = Eval(stmt, env)
+		result = in.Eval(stmt, env)
 
 		switch result := result.(type) {
 		case *object.ReturnValue:
-			return result.Value
+			return in.complete(result.Value)
 		case *object.Error:
 			return result
 		}
@@ -112,7 +143,7 @@ func evalProgram(stmts []ast.Statement, env *object.Environment) object.Object {
 	return result
 }
 
-func evalExpressions(
+func (in *Interpreter) evalExpressions(
 	exps []ast.Expression,
 	env *object.Environment,
 ) []object.Object {
@@ -120,7 +151,7 @@ func evalExpressions(
 	var result []object.Object
 
 	for _, e := range exps {
-		evaluated := Eval(e, env)
+		evaluated := in.Eval(e, env)
 		if isError(evaluated) {
 			return []object.Object{evaluated}
 		}
@@ -129,7 +160,7 @@ func evalExpressions(
 	return result
 }
 
-func evalBlockStatement(
+func (in *Interpreter) evalBlockStatement(
 	block *ast.BlockStatement,
 	env *object.Environment,
 ) object.Object {
@@ -137,7 +168,7 @@ func evalBlockStatement(
 	var result object.Object
 
 	for _, stmt := range block.Statements {
-		result = Eval(stmt, env)
+		result = in.Eval(stmt, env)
 
 		if result != nil {
 			rt := result.Type()
@@ -149,15 +180,15 @@ func evalBlockStatement(
 	return result
 }
 
-func evalIdentifier(
+func (in *Interpreter) evalIdentifier(
 	node *ast.Identifier,
 	env *object.Environment,
 ) object.Object {
 
-	if val, ok := env.Get(node.Value); ok {
+	if val, ok := env.Lookup(node); ok {
 		return val
 	}
-	if builtin, ok := builtins[node.Value]; ok {
+	if builtin, ok := in.builtins[node.Value]; ok {
 		return builtin
 	}
 
@@ -204,7 +235,7 @@ func evalMinusPrefixOperator(right object.Object) object.Object {
 	return &object.Integer{Value: -value}
 }
 
-func evalInfixExpression(
+func (in *Interpreter) evalInfixExpression(
 	operator string,
 	left object.Object,
 	right object.Object,
@@ -221,7 +252,7 @@ func evalInfixExpression(
 		return newError("type mismatch: %s %s %s",
 			left.Type(), operator, right.Type())
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
-		return evalStringInfixExpression(operator, left, right)
+		return in.evalStringInfixExpression(operator, left, right)
 	default:
 		return newError("unknown operation: %s %s %s",
 			left.Type(), operator, right.Type())
@@ -245,6 +276,9 @@ func evalIntegerInfixExpression(
 	case "*":
 		return &object.Integer{Value: leftVal * rightVal}
 	case "/":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
 		return &object.Integer{Value: leftVal / rightVal}
 	case "<":
 		return booleanObject(leftVal < rightVal)
@@ -260,21 +294,25 @@ func evalIntegerInfixExpression(
 	}
 }
 
-func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
-	condition := Eval(ie.Condition, env)
+func (in *Interpreter) evalIfExpression(
+	ie *ast.IfExpression,
+	env *object.Environment,
+) object.Object {
+
+	condition := in.Eval(ie.Condition, env)
 	if isError(condition) {
 		return condition
 	}
 
 	if isTrue(condition) {
-		return Eval(ie.Consequence, env)
+		return in.Eval(ie.Consequence, env)
 	} else if ie.Alternative != nil {
-		return Eval(ie.Alternative, env)
+		return in.Eval(ie.Alternative, env)
 	}
 	return NULL
 }
 
-func evalStringInfixExpression(
+func (in *Interpreter) evalStringInfixExpression(
 	operator string,
 	left object.Object,
 	right object.Object,
@@ -287,6 +325,11 @@ func evalStringInfixExpression(
 	leftVal := left.(*object.String).Value
 	rightVal := right.(*object.String).Value
 
+	// Check the limits before concatenating, so that oversized strings are
+	// never built in the first place.
+	if err := in.alloc(int64(len(leftVal) + len(rightVal))); err != nil {
+		return err
+	}
 	return &object.String{Value: leftVal + rightVal}
 }
 
@@ -296,6 +339,8 @@ func evalIndexExpression(left, index object.Object) object.Object {
 		return evalArrayIndexExpression(left, index)
 	case left.Type() == object.HASH_OBJ:
 		return evalHashIndexExpression(left, index)
+	case left.Type() == object.MODULE_OBJ:
+		return evalModuleIndexExpression(left, index)
 	default:
 		return newError("invalid index operator: %s", left.Type())
 	}
@@ -312,15 +357,18 @@ func evalArrayIndexExpression(array, index object.Object) object.Object {
 	return arrayObj.Elements[idx]
 }
 
-func evalHashLiteral(
+func (in *Interpreter) evalHashLiteral(
 	node *ast.HashLiteral,
 	env *object.Environment,
 ) object.Object {
 
-	pairs := make(map[object.HashKey]object.HashPair)
+	hash, err := in.newHash(len(node.Keys))
+	if err != nil {
+		return err
+	}
 
-	for keyNode, valueNode := range node.Pairs {
-		k := Eval(keyNode, env)
+	for _, keyNode := range node.Keys {
+		k := in.Eval(keyNode, env)
 		if isError(k) {
 			return k
 		}
@@ -330,15 +378,14 @@ func evalHashLiteral(
 			return newError("invalid as hash key: %s", k.Type())
 		}
 
-		v := Eval(valueNode, env)
+		v := in.Eval(node.Pairs[keyNode], env)
 		if isError(v) {
 			return v
 		}
 
-		hashed := hashKey.HashKey()
-		pairs[hashed] = object.HashPair{Key: k, Value: v}
+		hash.Set(hashKey.HashKey(), object.HashPair{Key: k, Value: v})
 	}
-	return &object.Hash{Pairs: pairs}
+	return hash
 }
 
 func evalHashIndexExpression(hash, index object.Object) object.Object {
@@ -356,16 +403,45 @@ func evalHashIndexExpression(hash, index object.Object) object.Object {
 	return pair.Value
 }
 
-func applyFunction(fn object.Object, args []object.Object) object.Object {
-	switch fn := fn.(type) {
-	case *object.Function:
-		extendedEnv := extendFunctionEnv(fn, args)
-		evaluated := Eval(fn.Body, extendedEnv)
-		return unwrapReturnValue(evaluated)
-	case *object.Builtin:
-		return fn.Fn(args...)
-	default:
-		return newError("not a function: %s", fn.Type())
+func evalModuleIndexExpression(module, index object.Object) object.Object {
+	moduleObject := module.(*object.Module)
+
+	name, ok := index.(*object.String)
+	if !ok {
+		return newError("invalid as module export: %s", index.Type())
+	}
+
+	value, ok := moduleObject.Get(name.Value)
+	if !ok {
+		return newError("module %q has no export %q", moduleObject.Path, name.Value)
+	}
+	return value
+}
+
+// applyFunction calls fn with args. Calls that functions make in tail
+// position are made here too, once the caller's body is done, so that they
+// take no more stack however deep they go.
+func (in *Interpreter) applyFunction(fn object.Object, args []object.Object) object.Object {
+	for {
+		switch f := fn.(type) {
+		case *object.Function:
+			if len(args) != len(f.Parameters) {
+				return newError("wrong number of arguments, expected %d, got %d",
+					len(f.Parameters), len(args))
+			}
+			extendedEnv := extendFunctionEnv(f, args)
+			evaluated := unwrapReturnValue(in.evalTailBlock(f.Body, extendedEnv))
+
+			call, ok := evaluated.(*tailCall)
+			if !ok {
+				return evaluated
+			}
+			fn, args = call.fn, call.args
+		case *object.Builtin:
+			return f.Fn(args...)
+		default:
+			return newError("not a function: %s", fn.Type())
+		}
 	}
 }
 
@@ -374,10 +450,17 @@ func extendFunctionEnv(
 	args []object.Object,
 ) *object.Environment {
 
-	env := object.NewEnclosedEnvironment(fn.Env)
+	if fn.Locals == nil {
+		env := object.NewEnclosedEnvironment(fn.Env)
+		for i, param := range fn.Parameters {
+			env.Insert(param.Value, args[i])
+		}
+		return env
+	}
 
+	env := object.NewFrame(fn.Env, fn.Locals)
 	for i, param := range fn.Parameters {
-		env.Insert(param.Value, args[i])
+		env.Define(param, args[i])
 	}
 	return env
 }
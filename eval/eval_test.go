@@ -1,6 +1,7 @@
 package eval
 
 import (
+	"bytes"
 	"testing"
 
 	"monkey/lexer"
@@ -135,6 +136,10 @@ func TestErrorHandling(t *testing.T) {
 			"-true",
 			"unknown operation: -BOOLEAN",
 		},
+		{
+			"let x = 0; 10 / x",
+			"division by zero",
+		},
 		{
 			"true + false;",
 			"unknown operation: BOOLEAN + BOOLEAN",
@@ -155,6 +160,10 @@ func TestErrorHandling(t *testing.T) {
 			`{"name": "foo"}[fn(x) { x }];`,
 			"invalid as hash key: FUNCTION",
 		},
+		{
+			"fn(x, y) { x }(1)",
+			"wrong number of arguments, expected 2, got 1",
+		},
 	} {
 		evaluated := testEval(tt.input)
 
@@ -220,6 +229,9 @@ func TestFunctionApplication(t *testing.T) {
 		{"let add = fn(x, y) { x + y; }; add(5, 5);", 10},
 		{"let add = fn(x, y) { x + y; }; add(5 + 5, add(5, 5));", 20},
 		{"fn(x) { x; }(5)", 5},
+		// Type annotations are left to the checker.
+		{"let add = fn(x: int, y: int) -> int { x + y; }; add(5, 5);", 10},
+		{"let n: string = 5; n;", 5},
 	} {
 		testIntegerObject(t, testEval(tt.input), tt.expected)
 	}
@@ -294,6 +306,17 @@ func TestBuiltinFunction(t *testing.T) {
 	}
 }
 
+func TestPrintln(t *testing.T) {
+	var out bytes.Buffer
+
+	evaluated := testEvalWith(New(WithOutput(&out)), `println("a", 1, [true])`)
+	testNullObject(t, evaluated)
+
+	if expected := "a\n1\n[true]\n"; out.String() != expected {
+		t.Errorf("Output mismatch. Expected %q, got %q", expected, out.String())
+	}
+}
+
 func TestArrayLiterals(t *testing.T) {
 	input := "[1, 2 * 2, 3 + 3]"
 
@@ -454,13 +477,147 @@ func TestHashIndexExpressions(t *testing.T) {
 	}
 }
 
+func TestNegativeAllocation(t *testing.T) {
+	in := New(WithLimits(Limits{MaxAllocated: 100}))
+
+	if err := in.alloc(-50); err == nil || err.Message != "invalid allocation of -50 bytes" {
+		t.Errorf("negative allocation not rejected. got=%v", err)
+	}
+	if in.Allocated() != 0 {
+		t.Errorf("allocated wrong. want=0, got=%d", in.Allocated())
+	}
+}
+
+func TestResourceLimits(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		limits   Limits
+		expected string
+	}{
+		{
+			`let double = fn(s, n) { if (n > 0) { double(s + s, n - 1) } else { s } }; double("x", 64);`,
+			Limits{MaxValueSize: 1024},
+			"resource limit exceeded: value of 2048 bytes exceeds 1024",
+		},
+		{
+			`let fill = fn(arr) { fill(append(arr, 1)) }; fill([]);`,
+			Limits{MaxAllocated: 1 << 20},
+			"resource limit exceeded: allocated more than 1048576 bytes",
+		},
+		{
+			// The literal fits, but not its tail on top of it.
+			`let a = [1, 2, 3, 4]; tail(a)`,
+			Limits{MaxAllocated: 7*arrayElementSize - 1},
+			"resource limit exceeded: allocated more than 111 bytes",
+		},
+		{
+			`[1, 2, 3]; {"a": 1, "b": 2}`,
+			Limits{MaxAllocated: 3*arrayElementSize + 2 + 2*hashPairSize - 1},
+			"resource limit exceeded: allocated more than 177 bytes",
+		},
+	} {
+		evaluated := testEvalWith(New(WithLimits(tt.limits)), tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("Object not Error. Got %T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("Message mismatch. Expected %q, got %q",
+				tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestAllocationAccounting(t *testing.T) {
+	in := New(WithLimits(Limits{MaxAllocated: 1024}))
+
+	testIntegerObject(t, testEvalWith(in, `len("ab" + "cd")`), 4)
+
+	// Two literals of 2 bytes each, plus their concatenation.
+	if in.Allocated() != 8 {
+		t.Errorf("Allocated mismatch. Expected 8, got %d", in.Allocated())
+	}
+}
+
+func TestPackageEval(t *testing.T) {
+	program := parser.New(lexer.New(`let double = fn(x) { x * 2 }; double(21)`)).ParseProgram()
+
+	testIntegerObject(t, Eval(program, object.NewEnv()), 42)
+}
+
+func TestResetAllocated(t *testing.T) {
+	in := New(WithLimits(Limits{MaxAllocated: 15}))
+
+	testObject(t, testEvalWith(in, `repeat("a", 10)`), "aaaaaaaaaa")
+	testObject(t, testEvalWith(in, `repeat("a", 10)`),
+		errorMessage("resource limit exceeded: allocated more than 15 bytes"))
+
+	in.ResetAllocated()
+	if in.Allocated() != 0 {
+		t.Errorf("Allocated mismatch. Expected 0, got %d", in.Allocated())
+	}
+	testObject(t, testEvalWith(in, `repeat("a", 10)`), "aaaaaaaaaa")
+}
+
+func TestCapabilities(t *testing.T) {
+	builtins["secret"] = builtin{
+		fn: func(in *Interpreter, args ...object.Object) object.Object {
+			return &object.String{Value: "secret"}
+		},
+		caps: CapFileRead | CapEnv,
+	}
+	defer delete(builtins, "secret")
+
+	for _, tt := range []struct {
+		in       *Interpreter
+		expected string
+	}{
+		{
+			New(),
+			"permission denied: `secret` requires file read, environment capability",
+		},
+		{
+			New(WithCapabilities(CapFileRead)),
+			"permission denied: `secret` requires environment capability",
+		},
+		{
+			New(HideForbidden(), WithCapabilities(CapEnv)),
+			"undefined identifier: secret",
+		},
+	} {
+		evaluated := testEvalWith(tt.in, "secret()")
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("Object not Error. Got %T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("Message mismatch. Expected %q, got %q",
+				tt.expected, errObj.Message)
+		}
+	}
+
+	evaluated := testEvalWith(New(WithCapabilities(AllCapabilities)), "secret()")
+	str, ok := evaluated.(*object.String)
+	if !ok || str.Value != "secret" {
+		t.Errorf("Granted builtin not called. Got %T (%+v)", evaluated, evaluated)
+	}
+}
+
 func testEval(input string) object.Object {
+	return testEvalWith(New(), input)
+}
+
+func testEvalWith(in *Interpreter, input string) object.Object {
 	l := lexer.New(input)
 	p := parser.New(l)
 	program := p.ParseProgram()
 	env := object.NewEnv()
 
-	return Eval(program, env)
+	return in.Eval(program, env)
 }
 
 func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
@@ -498,3 +655,71 @@ func testNullObject(t *testing.T, obj object.Object) bool {
 	}
 	return true
 }
+
+// errorMessage is used in tests expecting an evaluation to fail, to tell
+// them apart from those expecting a string.
+type errorMessage string
+
+// testObject checks obj against expected, which may be an int, a bool, a
+// string, an errorMessage, nil for null, or a slice of them for arrays.
+func testObject(t *testing.T, obj object.Object, expected interface{}) bool {
+	switch expected := expected.(type) {
+	case int:
+		return testIntegerObject(t, obj, int64(expected))
+	case bool:
+		return testBooleanObject(t, obj, expected)
+	case string:
+		return testStringObject(t, obj, expected)
+	case errorMessage:
+		return testErrorObject(t, obj, string(expected))
+	case nil:
+		return testNullObject(t, obj)
+	case []interface{}:
+		arr, ok := obj.(*object.Array)
+		if !ok {
+			t.Errorf("Object not Array. Got %T (%+v)", obj, obj)
+			return false
+		}
+		if len(arr.Elements) != len(expected) {
+			t.Errorf("Array length mismatch. Expected %d, got %d (%s)",
+				len(expected), len(arr.Elements), arr.Inspect())
+			return false
+		}
+		for i, e := range expected {
+			if !testObject(t, arr.Elements[i], e) {
+				return false
+			}
+		}
+		return true
+	}
+	t.Fatalf("Unsupported expectation %T", expected)
+	return false
+}
+
+func testStringObject(t *testing.T, obj object.Object, expected string) bool {
+	result, ok := obj.(*object.String)
+	if !ok {
+		t.Errorf("Object not String. Got %T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("Object.Value mismatch. Expected %q, got %q",
+			expected, result.Value)
+		return false
+	}
+	return true
+}
+
+func testErrorObject(t *testing.T, obj object.Object, expected string) bool {
+	result, ok := obj.(*object.Error)
+	if !ok {
+		t.Errorf("Object not Error. Got %T (%+v)", obj, obj)
+		return false
+	}
+	if result.Message != expected {
+		t.Errorf("Message mismatch. Expected %q, got %q",
+			expected, result.Message)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package eval
+
+import (
+	"io"
+	"io/fs"
+	"math/rand"
+	"os"
+	"regexp"
+	"sort"
+	"time"
+
+	"monkey/object"
+)
+
+// Interpreter holds the state shared by every evaluation performed through
+// it, such as resource accounting, granted capabilities and the builtins
+// bound to it.
+type Interpreter struct {
+	limits    Limits
+	allocated int64
+
+	granted       Capability
+	hideForbidden bool
+
+	// out is where builtins such as `println` write.
+	out io.Writer
+	// root is the directory file builtins resolve paths against.
+	root string
+	// modules is where imported modules are read from, and loaded caches
+	// them by path. importing holds the paths of the modules being
+	// evaluated, innermost last.
+	modules   fs.FS
+	loaded    map[string]*object.Module
+	importing []string
+	// clock is what time builtins read and sleep on.
+	clock Clock
+	// rand is the source of every random number generated by scripts.
+	rand *rand.Rand
+	// regexps caches the patterns compiled by the regular expression
+	// builtins.
+	regexps map[string]*regexp.Regexp
+
+	builtins map[string]*object.Builtin
+}
+
+type Option func(*Interpreter)
+
+func New(opts ...Option) *Interpreter {
+	in := &Interpreter{out: os.Stdout, clock: systemClock{}}
+
+	for _, opt := range opts {
+		opt(in)
+	}
+	if in.rand == nil {
+		in.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	in.builtins = make(map[string]*object.Builtin, len(builtins))
+	for name, b := range builtins {
+		if in.hideForbidden && !in.Granted(b.caps) {
+			continue
+		}
+		in.builtins[name] = in.guard(name, b)
+	}
+	return in
+}
+
+// WithOutput makes scripts print to w rather than to the standard output.
+func WithOutput(w io.Writer) Option {
+	return func(in *Interpreter) {
+		in.out = w
+	}
+}
+
+// WithSeed seeds the random numbers generated by scripts, so that runs can be
+// reproduced. Without it, a seed based on the current time is used.
+func WithSeed(seed int64) Option {
+	return func(in *Interpreter) {
+		in.rand = rand.New(rand.NewSource(seed))
+	}
+}
+
+func (in *Interpreter) bind(fn builtinFunction) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			return fn(in, args...)
+		},
+	}
+}
+
+// BuiltinNames returns the names of the builtins visible to scripts, in
+// sorted order.
+func (in *Interpreter) BuiltinNames() []string {
+	names := make([]string, 0, len(in.builtins))
+	for name := range in.builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
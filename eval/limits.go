@@ -0,0 +1,93 @@
+package eval
+
+import "monkey/object"
+
+// Estimated sizes, in bytes, used when accounting for allocations. An array
+// element is an interface value, and a hash pair holds a key and two
+// interface values along with some map overhead.
+const (
+	arrayElementSize = 16
+	hashPairSize     = 64
+)
+
+// Limits bounds the memory an interpreter may allocate for strings, arrays
+// and hashes. A zero value for any field means that it is unlimited.
+type Limits struct {
+	// MaxAllocated is the total number of bytes that may be allocated,
+	// counting every value made whether or not it is still in use. It
+	// bounds the work done rather than the memory kept alive. The count
+	// only starts over with ResetAllocated, which long-lived interpreters,
+	// such as those of a REPL or a server, should call before each Eval.
+	MaxAllocated int64
+	// MaxValueSize is the number of bytes a single value may occupy.
+	MaxValueSize int64
+}
+
+func WithLimits(limits Limits) Option {
+	return func(in *Interpreter) {
+		in.limits = limits
+	}
+}
+
+// Allocated reports the number of bytes accounted for so far.
+func (in *Interpreter) Allocated() int64 {
+	return in.allocated
+}
+
+// ResetAllocated starts counting allocated bytes over, for an interpreter
+// running several programs that should each get the whole of MaxAllocated.
+func (in *Interpreter) ResetAllocated() {
+	in.allocated = 0
+}
+
+// alloc accounts for size more bytes. Negative sizes, which only come from
+// overflowing computations, are refused rather than giving bytes back.
+func (in *Interpreter) alloc(size int64) *object.Error {
+	if err := in.fits(size); err != nil {
+		return err
+	}
+	in.allocated += size
+
+	return nil
+}
+
+// fits reports whether a value of size bytes could be allocated, without
+// accounting for it, so that values built piece by piece can be checked as
+// they grow and accounted for once done.
+func (in *Interpreter) fits(size int64) *object.Error {
+	if size < 0 {
+		return newError("invalid allocation of %d bytes", size)
+	}
+	if in.limits.MaxValueSize > 0 && size > in.limits.MaxValueSize {
+		return newError("resource limit exceeded: value of %d bytes exceeds %d",
+			size, in.limits.MaxValueSize)
+	}
+	if in.limits.MaxAllocated > 0 && in.allocated+size > in.limits.MaxAllocated {
+		return newError("resource limit exceeded: allocated more than %d bytes",
+			in.limits.MaxAllocated)
+	}
+	return nil
+}
+
+func (in *Interpreter) newString(value string) object.Object {
+	if err := in.alloc(int64(len(value))); err != nil {
+		return err
+	}
+	return &object.String{Value: value}
+}
+
+func (in *Interpreter) newArray(elements []object.Object) object.Object {
+	if err := in.alloc(int64(len(elements)) * arrayElementSize); err != nil {
+		return err
+	}
+	return &object.Array{Elements: elements}
+}
+
+// newHash returns an empty hash, accounting up front for the given number of
+// pairs.
+func (in *Interpreter) newHash(size int) (*object.Hash, *object.Error) {
+	if err := in.alloc(int64(size) * hashPairSize); err != nil {
+		return nil, err
+	}
+	return object.NewHash(), nil
+}
@@ -0,0 +1,208 @@
+package eval
+
+import (
+	"strconv"
+
+	"monkey/ast"
+	"monkey/object"
+	"monkey/token"
+)
+
+// Macros expanding to further macro calls are expanded again, up to this
+// many times.
+const maxMacroDepth = 100
+
+// expandMacros binds the macros defined by top-level let statements of
+// program in env, removing their definitions, and then replaces every call
+// to a macro bound in env with the code it returns.
+//
+// Expansion is not hygienic: names in the returned code are resolved where
+// the macro is called. Only the parameters of a macro are kept apart, in an
+// environment of its own.
+func (in *Interpreter) expandMacros(program *ast.Program, env *object.Environment) *object.Error {
+	stmts := program.Statements[:0]
+	for _, stmt := range program.Statements {
+		let, ok := stmt.(*ast.LetStatement)
+		if !ok || let == nil {
+			stmts = append(stmts, stmt)
+			continue
+		}
+		lit, ok := let.Value.(*ast.MacroLiteral)
+		if !ok {
+			stmts = append(stmts, stmt)
+			continue
+		}
+		env.Insert(let.Name.Value, &object.Macro{
+			Parameters: lit.Parameters,
+			Body:       lit.Body,
+			Env:        env,
+		})
+	}
+	program.Statements = stmts
+
+	var err *object.Error
+	ast.Modify(program, in.macroExpander(env, 0, &err))
+
+	return err
+}
+
+// macroExpander returns a modifier expanding macro calls, which records the
+// first error it runs into in err.
+func (in *Interpreter) macroExpander(
+	env *object.Environment,
+	depth int,
+	err **object.Error,
+) ast.ModifierFunc {
+
+	return func(node ast.Node) ast.Node {
+		if *err != nil {
+			return node
+		}
+		call, ok := node.(*ast.CallExpression)
+		if !ok {
+			return node
+		}
+		ident, ok := call.Function.(*ast.Identifier)
+		if !ok {
+			return node
+		}
+		obj, ok := env.Get(ident.Value)
+		if !ok {
+			return node
+		}
+		macro, ok := obj.(*object.Macro)
+		if !ok {
+			return node
+		}
+
+		if depth >= maxMacroDepth {
+			*err = newError("macro expansion deeper than %d levels", maxMacroDepth)
+			return node
+		}
+		if len(call.Arguments) != len(macro.Parameters) {
+			*err = newError("wrong number of arguments to macro %s, expected %d, got %d",
+				ident.Value, len(macro.Parameters), len(call.Arguments))
+			return node
+		}
+
+		macroEnv := object.NewEnclosedEnvironment(macro.Env)
+		for i, param := range macro.Parameters {
+			macroEnv.Insert(param.Value, &object.Quote{Node: call.Arguments[i]})
+		}
+		result := in.complete(unwrapReturnValue(in.Eval(macro.Body, macroEnv)))
+		if e, ok := result.(*object.Error); ok {
+			*err = e
+			return node
+		}
+		quote, ok := result.(*object.Quote)
+		if !ok {
+			*err = newError("macro %s must return a quote, got %s", ident.Value,
+				typeOf(result))
+			return node
+		}
+		return ast.Modify(quote.Node, in.macroExpander(env, depth+1, err))
+	}
+}
+
+// quote returns node as code, without evaluating it apart from the calls to
+// `unquote` within it, which are replaced with the code for their value.
+func (in *Interpreter) quote(node ast.Node, env *object.Environment) object.Object {
+	var err *object.Error
+
+	// Work on a copy, so that the same quote can be evaluated many times.
+	node = ast.Modify(ast.Copy(node), func(node ast.Node) ast.Node {
+		if err != nil {
+			return node
+		}
+		call, ok := node.(*ast.CallExpression)
+		if !ok || !isIdentifier(call.Function, "unquote") {
+			return node
+		}
+		if len(call.Arguments) != 1 {
+			err = newError("wrong number of arguments to unquote, expected 1, got %d",
+				len(call.Arguments))
+			return node
+		}
+
+		value := in.Eval(call.Arguments[0], env)
+		if e, ok := value.(*object.Error); ok {
+			err = e
+			return node
+		}
+		code, e := objectToNode(value)
+		if e != nil {
+			err = e
+			return node
+		}
+		return code
+	})
+	if err != nil {
+		return err
+	}
+	return &object.Quote{Node: node}
+}
+
+// objectToNode returns the code that evaluates to obj.
+func objectToNode(obj object.Object) (ast.Node, *object.Error) {
+	switch obj := obj.(type) {
+	case *object.Quote:
+		// Copy the code, as it may be unquoted in several places, each of
+		// which gets resolved on its own.
+		return ast.Copy(obj.Node), nil
+	case *object.Integer:
+		literal := strconv.FormatInt(obj.Value, 10)
+		tok := token.Token{Type: token.INT, Literal: literal}
+		return &ast.IntegerLiteral{Token: tok, Value: obj.Value}, nil
+	case *object.Boolean:
+		tok := token.Token{Type: token.FALSE, Literal: "false"}
+		if obj.Value {
+			tok = token.Token{Type: token.TRUE, Literal: "true"}
+		}
+		return &ast.Boolean{Token: tok, Value: obj.Value}, nil
+	case *object.String:
+		tok := token.Token{Type: token.STRING, Literal: obj.Value}
+		return &ast.StringLiteral{Token: tok, Value: obj.Value}, nil
+	case *object.Array:
+		lit := &ast.ArrayLiteral{Token: token.Token{Type: token.LBRACKET, Literal: "["}}
+		for _, e := range obj.Elements {
+			node, err := objectToNode(e)
+			if err != nil {
+				return nil, err
+			}
+			lit.Elements = append(lit.Elements, node.(ast.Expression))
+		}
+		return lit, nil
+	case *object.Hash:
+		lit := &ast.HashLiteral{
+			Token: token.Token{Type: token.LBRACE, Literal: "{"},
+			Pairs: make(map[ast.Expression]ast.Expression),
+		}
+		for _, pair := range obj.OrderedPairs() {
+			key, err := objectToNode(pair.Key)
+			if err != nil {
+				return nil, err
+			}
+			value, err := objectToNode(pair.Value)
+			if err != nil {
+				return nil, err
+			}
+			lit.Pairs[key.(ast.Expression)] = value.(ast.Expression)
+			lit.Keys = append(lit.Keys, key.(ast.Expression))
+		}
+		return lit, nil
+	default:
+		return nil, newError("cannot unquote %s", typeOf(obj))
+	}
+}
+
+func isIdentifier(e ast.Expression, name string) bool {
+	ident, ok := e.(*ast.Identifier)
+	return ok && ident.Value == name
+}
+
+func typeOf(obj object.Object) object.ObjectType {
+	if obj == nil {
+		return object.NULL_OBJ
+	}
+	return obj.Type()
+}
@@ -0,0 +1,116 @@
+package eval
+
+import (
+	"bytes"
+	"testing"
+
+	"monkey/object"
+)
+
+func TestQuoteUnquote(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected string
+	}{
+		{`quote(5)`, `5`},
+		{`quote(5 + 8)`, `(5 + 8)`},
+		{`quote(foobar + barfoo)`, `(foobar + barfoo)`},
+		{`quote(unquote(4 + 4))`, `8`},
+		{`quote(8 + unquote(4 + 4))`, `(8 + 8)`},
+		{`quote(unquote(4 + 4) + 8)`, `(8 + 8)`},
+		{`let foobar = 8; quote(unquote(foobar))`, `8`},
+		{`quote(unquote(true == false))`, `false`},
+		{`quote(unquote("a" + "b"))`, `ab`},
+		{`quote(unquote([1, [true]]))`, `[1, [true]]`},
+		{`quote(unquote(quote(4 + 4)))`, `(4 + 4)`},
+		{`let q = quote(4 + 4); quote(unquote(4 + 4) + unquote(q))`, `(8 + (4 + 4))`},
+	} {
+		evaluated := testEval(tt.input)
+		quote, ok := evaluated.(*object.Quote)
+		if !ok {
+			t.Errorf("%s: expected *object.Quote. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if quote.Node == nil {
+			t.Errorf("%s: quote.Node is nil", tt.input)
+			continue
+		}
+		if quote.Node.String() != tt.expected {
+			t.Errorf("%s: not equal. got=%q, want=%q", tt.input, quote.Node.String(), tt.expected)
+		}
+	}
+}
+
+func TestQuoteIsCopied(t *testing.T) {
+	input := `
+		let f = fn(x) { quote(unquote(x) + 1) };
+		let a = f(1);
+		let b = f(2);
+		[str(a), str(b)]`
+
+	testObject(t, testEval(input), []interface{}{"1 + 1", "2 + 1"})
+}
+
+func TestMacros(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected interface{}
+	}{
+		{`let infix = macro() { quote(1 + 2) }; infix()`, 3},
+		{`let reverse = macro(a, b) { quote(unquote(b) - unquote(a)) }; reverse(2 + 2, 10 - 5)`, 1},
+		{`
+			let unless = macro(cond, then, otherwise) {
+				quote(if (!(unquote(cond))) { unquote(then) } else { unquote(otherwise) })
+			};
+			unless(10 > 5, "not greater", "greater")`, "greater"},
+		{`
+			let unless = macro(cond, then, otherwise) {
+				quote(if (!(unquote(cond))) { unquote(then) } else { unquote(otherwise) })
+			};
+			let f = fn(x) { unless(x > 5, "small", "big") };
+			[f(1), f(10)]`, []interface{}{"small", "big"}},
+		{`
+			let twice = macro(e) { quote([unquote(e), unquote(e)]) };
+			let next = fn() { 1 };
+			twice(next())`, []interface{}{1, 1}},
+		{`
+			let inc = macro(e) { quote(unquote(e) + 1) };
+			let inc_twice = macro(e) { quote(inc(inc(unquote(e)))) };
+			inc_twice(40)`, 42},
+		{`let m = macro(x) { quote(unquote(x) * 2) }; let x = 3; m(x + 1)`, 8},
+		{`let m = macro(x) { quote(x) }; m(1)`, errorMessage("undefined identifier: x")},
+		{`let m = macro(x) { x }; m(1)`, 1},
+		{`let m = macro(x) { 1 }; m(2)`, errorMessage("macro m must return a quote, got INTEGER")},
+		{`let m = macro(a, b) { a }; m(1)`,
+			errorMessage("wrong number of arguments to macro m, expected 2, got 1")},
+		{`let loop = macro(x) { quote(loop(unquote(x))) }; loop(1)`,
+			errorMessage("macro expansion deeper than 100 levels")},
+		{`let m = macro(x) { quote(unquote(fn() {})) }; m(1)`,
+			errorMessage("cannot unquote FUNCTION")},
+		{`let m = macro(x) { x }; let f = m; f(1)`, errorMessage("not a function: MACRO")},
+		{`quote(1, 2)`, errorMessage("wrong number of arguments to quote, expected 1, got 2")},
+		{`quote(unquote())`, errorMessage("wrong number of arguments to unquote, expected 1, got 0")},
+	} {
+		testObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestAssertEqMacro(t *testing.T) {
+	input := `
+		let assert_eq = macro(actual, expected) {
+			quote(if (unquote(actual) != unquote(expected)) {
+				println("assertion failed: " + unquote(str(actual)) + " != " + unquote(str(expected)));
+			})
+		};
+		let double = fn(x) { x * 2 };
+		assert_eq(double(2), 4);
+		assert_eq(double(3), 2 + 3);`
+
+	var out bytes.Buffer
+	testEvalWith(New(WithOutput(&out)), input)
+
+	expected := "assertion failed: double(3) != 2 + 3\n"
+	if out.String() != expected {
+		t.Errorf("wrong output. want=%q, got=%q", expected, out.String())
+	}
+}
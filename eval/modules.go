@@ -0,0 +1,120 @@
+package eval
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+)
+
+// ModuleExt is appended to import paths that have no extension.
+const ModuleExt = ".mk"
+
+// WithModules makes `import` read modules from fsys. Paths imported by the
+// main program are relative to the root of fsys, and those imported by a
+// module are relative to the directory holding it. Without it, importing
+// always fails.
+func WithModules(fsys fs.FS) Option {
+	return func(in *Interpreter) {
+		in.modules = fsys
+	}
+}
+
+// importModule evaluates the module at name, unless it was already imported,
+// and returns its exports.
+func (in *Interpreter) importModule(name string) object.Object {
+	if in.modules == nil {
+		return newError("no module filesystem configured for import")
+	}
+	p, err := in.resolveModule(name)
+	if err != nil {
+		return err
+	}
+
+	if module, ok := in.loaded[p]; ok {
+		return module
+	}
+	for i, importing := range in.importing {
+		if importing == p {
+			cycle := append(in.importing[i:len(in.importing):len(in.importing)], p)
+			return newError("import cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	src, ioErr := fs.ReadFile(in.modules, p)
+	if ioErr != nil {
+		return fileError("import", name, ioErr)
+	}
+	par := parser.New(lexer.New(string(src)))
+	program := par.ParseProgram()
+	if len(par.Errors()) != 0 {
+		return newError("could not parse module %q: %s", p, par.Errors()[0])
+	}
+
+	in.importing = append(in.importing, p)
+	module := in.evalModule(p, program)
+	in.importing = in.importing[:len(in.importing)-1]
+
+	if isError(module) {
+		return module
+	}
+	if in.loaded == nil {
+		in.loaded = make(map[string]*object.Module)
+	}
+	in.loaded[p] = module.(*object.Module)
+
+	return module
+}
+
+// resolveModule returns the path of the module imported as name from the
+// module being evaluated.
+func (in *Interpreter) resolveModule(name string) (string, *object.Error) {
+	dir := "."
+	if len(in.importing) > 0 {
+		dir = path.Dir(in.importing[len(in.importing)-1])
+	}
+
+	p := path.Join(dir, name)
+	if path.Ext(p) == "" {
+		p += ModuleExt
+	}
+	if path.IsAbs(name) || !fs.ValidPath(p) {
+		return "", newError("module path %q is outside of the module root", name)
+	}
+	return p, nil
+}
+
+// evalModule runs program in an environment of its own, and gathers the
+// bindings of its export statements once it is done. Exports whose
+// statements were never reached, such as those after a return, are errors.
+func (in *Interpreter) evalModule(p string, program *ast.Program) object.Object {
+	env := object.NewEnv()
+
+	if result := in.Eval(program, env); isError(result) {
+		return result
+	}
+
+	names := []string{}
+	for _, stmt := range program.Statements {
+		if export, ok := stmt.(*ast.ExportStatement); ok {
+			names = append(names, export.Statement.Name.Value)
+		}
+	}
+	exports, err := in.newHash(len(names))
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		value, ok := env.Get(name)
+		if !ok {
+			return newError("module %q does not define export %q", p, name)
+		}
+		key := &object.String{Value: name}
+		exports.Set(key.HashKey(), object.HashPair{Key: key, Value: value})
+	}
+	return &object.Module{Path: p, Exports: exports}
+}
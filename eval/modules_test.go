@@ -0,0 +1,87 @@
+package eval
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"monkey/object"
+)
+
+func TestImport(t *testing.T) {
+	fsys := fstest.MapFS{
+		"lib/math.mk": {Data: []byte(`
+			let square = fn(x) { x * x };
+			export let double = fn(x) { x * 2 };
+			export let quad = fn(x) { double(double(x)) };
+			export let area = fn(x) { square(x) };
+		`)},
+		"lib/shapes.mk": {Data: []byte(`
+			let m = import "math";
+			export let unit = m["area"](1);
+		`)},
+		"cycle/a.mk":   {Data: []byte(`export let a = import "b";`)},
+		"cycle/b.mk":   {Data: []byte(`export let b = import "c.mk";`)},
+		"cycle/c.mk":   {Data: []byte(`export let c = import "a";`)},
+		"broken.mk":    {Data: []byte(`export let = 1;`)},
+		"failing.mk":   {Data: []byte(`export let x = 1 + true;`)},
+		"nested/in.mk": {Data: []byte(`export let up = import "../lib/shapes"["unit"];`)},
+		"early.mk":     {Data: []byte(`export let a = 1; return a; export let b = 2;`)},
+	}
+
+	for _, tt := range []struct {
+		input    string
+		expected interface{}
+	}{
+		{`let m = import "lib/math"; m["quad"](3)`, 12},
+		{`let m = import "lib/math.mk"; m["area"](3)`, 9},
+		{`(import "lib/shapes")["unit"]`, 1},
+		{`(import "nested/in")["up"]`, 1},
+		{`let m = import "lib/math"; m["square"]`,
+			errorMessage(`module "lib/math.mk" has no export "square"`)},
+		{`let m = import "lib/math"; m[1]`, errorMessage("invalid as module export: INTEGER")},
+		{`let m = import "lib/math"; square`, errorMessage("undefined identifier: square")},
+		{`type(import "lib/math")`, "MODULE"},
+		{`import "cycle/a"`,
+			errorMessage("import cycle: cycle/a.mk -> cycle/b.mk -> cycle/c.mk -> cycle/a.mk")},
+		{`import "missing"`, errorMessage(`could not import "missing": file does not exist`)},
+		{`import "../outside"`, errorMessage(`module path "../outside" is outside of the module root`)},
+		{`import "/etc/passwd"`, errorMessage(`module path "/etc/passwd" is outside of the module root`)},
+		{`import "broken"`,
+			errorMessage(`could not parse module "broken.mk": 1:12: Expected token: 'IDENT'. Got '='.`)},
+		{`import "failing"`, errorMessage("type mismatch: INTEGER + BOOLEAN")},
+		{`import "early"`, errorMessage(`module "early.mk" does not define export "b"`)},
+	} {
+		in := New(WithModules(fsys))
+		testObject(t, testEvalWith(in, tt.input), tt.expected)
+	}
+}
+
+func TestImportCache(t *testing.T) {
+	fsys := fstest.MapFS{
+		"counter.mk": {Data: []byte(`println("loading"); export let count = 1;`)},
+	}
+	var out strings.Builder
+	in := New(WithModules(fsys), WithOutput(&out))
+
+	result := testEvalWith(in, `let a = import "counter"; let b = import "./counter.mk"; a == b`)
+	if result != TRUE {
+		t.Errorf("expected both imports to give the same module, got %s", result.Inspect())
+	}
+	if out.String() != "loading\n" {
+		t.Errorf("expected the module to be evaluated once, got output %q", out.String())
+	}
+}
+
+func TestImportWithoutModules(t *testing.T) {
+	testObject(t, testEval(`import "lib"`),
+		errorMessage("no module filesystem configured for import"))
+}
+
+func TestModuleInspect(t *testing.T) {
+	module := &object.Module{Path: "lib/math.mk", Exports: object.NewHash()}
+
+	if module.Inspect() != `module "lib/math.mk"` {
+		t.Errorf("unexpected inspection: %s", module.Inspect())
+	}
+}
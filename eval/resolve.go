@@ -0,0 +1,83 @@
+package eval
+
+import "monkey/ast"
+
+// resolve annotates the identifiers of program with where their variables
+// live, so that those local to a function are found in a slot of its frame
+// rather than by name. Variables of the top level stay looked up by name,
+// since they may be defined later, such as by another input of the REPL.
+//
+// Every variable defined anywhere in a function gets a slot, whether it is
+// defined before or after being used. A slot that is still empty when read
+// makes the lookup go on by name, as it would have without slots.
+func resolve(program *ast.Program) {
+	r := &resolver{}
+	for _, stmt := range program.Statements {
+		ast.Inspect(stmt, r.visit)
+	}
+}
+
+type resolver struct {
+	// functions holds the slots of the enclosing functions, innermost last.
+	functions []map[string]int
+}
+
+func (r *resolver) visit(node ast.Node) bool {
+	switch node := node.(type) {
+	case *ast.Identifier:
+		r.identifier(node)
+	case *ast.FunctionLiteral:
+		r.function(node)
+		return false
+	case *ast.MacroLiteral:
+		// Macro bodies are run while expanding, before resolving, and with
+		// environments of their own.
+		return false
+	}
+	return true
+}
+
+func (r *resolver) identifier(ident *ast.Identifier) {
+	for depth := 0; depth < len(r.functions); depth++ {
+		slots := r.functions[len(r.functions)-1-depth]
+		if index, ok := slots[ident.Value]; ok {
+			ident.Scope = ast.LocalScope
+			ident.Depth = depth
+			ident.Index = index
+			return
+		}
+	}
+	ident.Scope = ast.GlobalScope
+}
+
+func (r *resolver) function(fn *ast.FunctionLiteral) {
+	slots := make(map[string]int)
+	locals := []string{}
+
+	declare := func(name string) {
+		if _, ok := slots[name]; !ok {
+			slots[name] = len(locals)
+			locals = append(locals, name)
+		}
+	}
+	for _, param := range fn.Parameters {
+		declare(param.Value)
+	}
+	ast.Inspect(fn.Body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.LetStatement:
+			declare(node.Name.Value)
+		case *ast.FunctionLiteral, *ast.MacroLiteral:
+			return false
+		}
+		return true
+	})
+	fn.Locals = locals
+
+	r.functions = append(r.functions, slots)
+	for _, param := range fn.Parameters {
+		r.identifier(param)
+	}
+	ast.Inspect(fn.Body, r.visit)
+	r.functions = r.functions[:len(r.functions)-1]
+}
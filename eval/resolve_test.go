@@ -0,0 +1,119 @@
+package eval
+
+import (
+	"testing"
+
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+)
+
+func TestResolve(t *testing.T) {
+	input := `
+		let g = 1;
+		let f = fn(a, b) {
+			let c = a + g;
+			fn(d) { a + c + d + h }
+		};`
+
+	program := parser.New(lexer.New(input)).ParseProgram()
+	resolve(program)
+
+	type resolution struct {
+		scope ast.Scope
+		depth int
+		index int
+	}
+	expected := map[string][]resolution{
+		"g": {{ast.GlobalScope, 0, 0}, {ast.GlobalScope, 0, 0}},
+		"f": {{ast.GlobalScope, 0, 0}},
+		"a": {{ast.LocalScope, 0, 0}, {ast.LocalScope, 0, 0}, {ast.LocalScope, 1, 0}},
+		"b": {{ast.LocalScope, 0, 1}},
+		"c": {{ast.LocalScope, 0, 2}, {ast.LocalScope, 1, 2}},
+		"d": {{ast.LocalScope, 0, 0}, {ast.LocalScope, 0, 0}},
+		"h": {{ast.GlobalScope, 0, 0}},
+	}
+
+	got := map[string][]resolution{}
+	ast.Inspect(program, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Identifier); ok {
+			got[ident.Value] = append(got[ident.Value],
+				resolution{ident.Scope, ident.Depth, ident.Index})
+		}
+		return true
+	})
+
+	for name, want := range expected {
+		if len(got[name]) != len(want) {
+			t.Errorf("%s: wrong resolutions. want=%v, got=%v", name, want, got[name])
+			continue
+		}
+		for i := range want {
+			if got[name][i] != want[i] {
+				t.Errorf("%s: wrong resolutions. want=%v, got=%v", name, want, got[name])
+				break
+			}
+		}
+	}
+
+	f := program.Statements[1].(*ast.LetStatement).Value.(*ast.FunctionLiteral)
+	if len(f.Locals) != 3 || f.Locals[0] != "a" || f.Locals[1] != "b" || f.Locals[2] != "c" {
+		t.Errorf("wrong locals. got=%q", f.Locals)
+	}
+}
+
+func TestResolvedEvaluation(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let f = fn(x) { let y = x * 2; y + 1 }; f(3)", 7},
+		{"let counter = fn() { let n = 0; fn() { n + 1 } }; counter()()", 1},
+		{"let adder = fn(a) { fn(b) { fn(c) { a + b + c } } }; adder(1)(2)(3)", 6},
+		{"let f = fn(x) { let x = x + 1; x }; f(1)", 2},
+		{"let f = fn(x, x) { x }; f(1, 2)", 2},
+		// Defined after the closure, but before it is called.
+		{"let f = fn() { let g = fn() { y }; let y = 2; g() }; f()", 2},
+		// Read before being defined in the function, so found further out.
+		{"let x = 1; let f = fn() { let a = x; let x = 2; a + x }; f()", 3},
+		{"let x = 1; let f = fn(c) { if (c) { let x = 5; }; x }; [f(true), f(false)]",
+			[]interface{}{5, 1}},
+		{"let f = fn() { if (false) { let z = 1; }; z }; f()",
+			errorMessage("undefined identifier: z")},
+		{"let f = fn() { later }; let later = 3; f()", 3},
+		{"let fib = fn(n) { if (n < 2) { n } else { fib(n - 1) + fib(n - 2) } }; fib(15)", 610},
+		{"let f = fn(len) { len }; [f(1), len([1, 2])]", []interface{}{1, 2}},
+		{"let f = fn(x) { quote(unquote(x) + 1) }; str(f(2))", "2 + 1"},
+	} {
+		testObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+// TestLaterGlobals checks that functions see globals defined by later
+// evaluations in the same environment, as in the REPL.
+func TestLaterGlobals(t *testing.T) {
+	in := New()
+	env := object.NewEnv()
+
+	for _, tt := range []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let f = fn(x) { x + y };", nil},
+		{"f(1)", errorMessage("undefined identifier: y")},
+		{"let y = 10;", nil},
+		{"f(1)", 11},
+		{"let y = 20; f(1)", 21},
+	} {
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+		result := in.Eval(program, env)
+		if tt.expected == nil {
+			if isError(result) {
+				t.Errorf("%s: unexpected error %s", tt.input, result.Inspect())
+			}
+			continue
+		}
+		testObject(t, result, tt.expected)
+	}
+}
@@ -0,0 +1,90 @@
+package eval
+
+import (
+	"monkey/ast"
+	"monkey/object"
+)
+
+// tailCall is a call to a function in tail position, which is left for
+// applyFunction to make once the calling function is done with its frame.
+// It never ends up in a variable: whoever unwraps a return value makes the
+// call, either in applyFunction or through complete.
+type tailCall struct {
+	fn   object.Object
+	args []object.Object
+}
+
+func (tc *tailCall) Type() object.ObjectType { return "TAIL_CALL" }
+func (tc *tailCall) Inspect() string         { return "tail call" }
+
+// complete makes the call obj stands for, if it is a tail call.
+func (in *Interpreter) complete(obj object.Object) object.Object {
+	if call, ok := obj.(*tailCall); ok {
+		return in.applyFunction(call.fn, call.args)
+	}
+	return obj
+}
+
+// evalTail evaluates e, which is in tail position: its value is the one of
+// the function it is in. Calls to functions are not made, but returned as a
+// tail call.
+func (in *Interpreter) evalTail(e ast.Expression, env *object.Environment) object.Object {
+	switch e := e.(type) {
+	case *ast.CallExpression:
+		if isIdentifier(e.Function, "quote") {
+			return in.Eval(e, env)
+		}
+		fn := in.Eval(e.Function, env)
+		if isError(fn) {
+			return fn
+		}
+		args := in.evalExpressions(e.Arguments, env)
+		if len(args) == 1 && isError(args[0]) {
+			return args[0]
+		}
+		if _, ok := fn.(*object.Function); ok {
+			return &tailCall{fn: fn, args: args}
+		}
+		return in.applyFunction(fn, args)
+	case *ast.IfExpression:
+		condition := in.Eval(e.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+		if isTrue(condition) {
+			return in.evalTailBlock(e.Consequence, env)
+		} else if e.Alternative != nil {
+			return in.evalTailBlock(e.Alternative, env)
+		}
+		return NULL
+	default:
+		return in.Eval(e, env)
+	}
+}
+
+// evalTailBlock evaluates block, whose last statement is in tail position.
+func (in *Interpreter) evalTailBlock(
+	block *ast.BlockStatement,
+	env *object.Environment,
+) object.Object {
+
+	last := len(block.Statements) - 1
+	if last < 0 {
+		return nil
+	}
+	for _, stmt := range block.Statements[:last] {
+		result := in.Eval(stmt, env)
+
+		if result != nil {
+			rt := result.Type()
+			if rt == object.RETURN_OBJ || rt == object.ERROR_OBJ {
+				return result
+			}
+		}
+	}
+
+	if stmt, ok := block.Statements[last].(*ast.ExpressionStatement); ok {
+		return in.evalTail(stmt.Expression, env)
+	}
+	return in.Eval(block.Statements[last], env)
+}
@@ -0,0 +1,53 @@
+package eval
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestTailCalls(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let sum = fn(n, acc) { if (n == 0) { acc } else { sum(n - 1, acc + n) } }; sum(100, 0)", 5050},
+		{"let sum = fn(n, acc) { if (n == 0) { return acc; } return sum(n - 1, acc + n); }; sum(100, 0)", 5050},
+		{"let sum = fn(n, acc) { if (n > 0) { return sum(n - 1, acc + n); }; acc }; sum(100, 0)", 5050},
+		{`
+			let even = fn(n) { if (n == 0) { true } else { odd(n - 1) } };
+			let odd = fn(n) { if (n == 0) { false } else { even(n - 1) } };
+			[even(10), odd(10), even(7)]`, []interface{}{true, false, false}},
+		{"let f = fn(x) { let g = fn(y) { x + y }; g(2) }; f(1)", 3},
+		{"let f = fn(xs) { len(xs) }; f([1, 2, 3])", 3},
+		{"let f = fn() { 1 }; let g = fn() { f(1) }; g()",
+			errorMessage("wrong number of arguments, expected 0, got 1")},
+		{"let f = fn() { 5() }; f()", errorMessage("not a function: INTEGER")},
+		{"let f = fn() { g() }; f()", errorMessage("undefined identifier: g")},
+		{"let f = fn() { 1 }; return f();", 1},
+		{"let f = fn(n) { n }; let g = fn() { return if (true) { f(2) } else { 3 }; }; g()", 2},
+		{"let f = fn() { if (false) { 1 } }; f()", nil},
+		{"let f = fn(n) { n * 2 }; map([1, 2], fn(x) { f(x) })", []interface{}{2, 4}},
+		{"let m = macro(x) { let q = fn(c) { c }; return q(x); }; m(1 + 1)", 2},
+	} {
+		testObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+// TestDeepTailRecursion checks that tail calls reuse the stack, by running a
+// million of them on a stack too small to hold even a fraction of as many
+// nested calls.
+func TestDeepTailRecursion(t *testing.T) {
+	defer debug.SetMaxStack(debug.SetMaxStack(16 << 20))
+
+	for _, input := range []string{
+		"let loop = fn(n, acc) { if (n == 0) { acc } else { loop(n - 1, acc + 1) } }; loop(1000000, 0)",
+		"let loop = fn(n, acc) { if (n == 0) { return acc; } return loop(n - 1, acc + 1); }; loop(1000000, 0)",
+		`let count = fn(xs, i, acc) {
+			if (i == len(xs)) { return acc; }
+			count(xs, i + 1, acc + xs[i])
+		};
+		count(map(range(1000000), fn(x) { 1 }), 0, 0)`,
+	} {
+		testObject(t, testEval(input), 1000000)
+	}
+}
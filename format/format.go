@@ -0,0 +1,81 @@
+// Package format prints Monkey programs in a canonical style, so that the
+// layout of source code no longer depends on who wrote it.
+//
+// Blocks are indented by four spaces and statements end with a semicolon,
+// except for `if` expressions. Parentheses are only kept where precedence
+// requires them. Arrays, hashes and calls that do not fit within 80 columns
+// have one element per line, and functions whose body is a single expression
+// are kept on one line when they fit. Comments stay where they were, except
+// for those within an expression spanning several lines, which are moved
+// after it.
+package format
+
+import (
+	"strings"
+
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/parser"
+)
+
+const (
+	INDENT = "    "
+	WIDTH  = 80
+)
+
+// SyntaxError holds the errors found while parsing the source to format.
+type SyntaxError struct {
+	Errors []string
+}
+
+func (e *SyntaxError) Error() string {
+	return strings.Join(e.Errors, "\n")
+}
+
+// Source formats src, which must be a valid program. A leading '#!' line is
+// kept as is.
+func Source(src string) (string, error) {
+	shebang := ""
+	if strings.HasPrefix(src, "#!") {
+		// Blank out the line rather than removing it, so that errors are
+		// reported at the right positions.
+		i := strings.IndexByte(src, '\n')
+		if i < 0 {
+			return src + "\n", nil
+		}
+		shebang, src = src[:i+1], src[i:]
+	}
+
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return "", &SyntaxError{Errors: p.Errors()}
+	}
+	return shebang + Program(program), nil
+}
+
+// Program returns the formatted source of program, which ends with a newline
+// unless it is empty.
+func Program(program *ast.Program) string {
+	p := &printer{comments: program.Comments}
+	p.statements(program.Statements, nil)
+
+	return p.out.String()
+}
+
+// Node returns the formatted source of a program, statement or expression.
+// Only a program ends with a newline; comments are left out of anything else.
+func Node(node ast.Node) string {
+	p := &printer{}
+	switch n := node.(type) {
+	case *ast.Program:
+		return Program(n)
+	case *ast.BlockStatement:
+		p.block(n)
+	case ast.Statement:
+		p.statements([]ast.Statement{n}, nil)
+	case ast.Expression:
+		p.expr(n)
+	}
+	return strings.TrimSuffix(p.out.String(), "\n")
+}
@@ -0,0 +1,256 @@
+package format
+
+import (
+	"strings"
+	"testing"
+
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/parser"
+)
+
+func TestSource(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected string
+	}{
+		{"", ""},
+		{"let   x=1", "let x = 1;\n"},
+		{"5 ; 6", "5;\n6;\n"},
+		{"let x = (1 + 2) * 3 - (4 - 5) - -6;", "let x = (1 + 2) * 3 - (4 - 5) - -6;\n"},
+		{"a * (b * c); (a * b) * c; -(a + b); (-a)[0]; -a[0]; !(a == b)",
+			"a * (b * c);\na * b * c;\n-(a + b);\n(-a)[0];\n-a[0];\n!(a == b);\n"},
+		{"(a + b)(c); (fn(x) { x })(1); f(a)(b)[c]",
+			"(a + b)(c);\nfn(x) { x }(1);\nf(a)(b)[c];\n"},
+		{`let s = "a  b";`, "let s = \"a  b\";\n"},
+		{"let f = fn(x) { x * 2 };", "let f = fn(x) { x * 2 };\n"},
+		{"let f = fn() {};", "let f = fn() {};\n"},
+		{"let f = fn(x) { return x; };", "let f = fn(x) {\n    return x;\n};\n"},
+		{"let f = fn(x) { let y = x; y };", "let f = fn(x) {\n    let y = x;\n    y;\n};\n"},
+		{"if (a) { b } else { c; d }", "if (a) {\n    b;\n} else {\n    c;\n    d;\n}\n"},
+		{"if (a) { b }", "if (a) { b }\n"},
+		{"if (a) { let b = 1; };\n(c)(d)", "if (a) {\n    let b = 1;\n}\nc(d);\n"},
+		{"if (a) { let b = 1; };\n(c + d)(e)", "if (a) {\n    let b = 1;\n};\n(c + d)(e);\n"},
+		{"if (a) { let b = 1; }; [1]", "if (a) {\n    let b = 1;\n};\n[1];\n"},
+		{"if (a) { let b = 1; }; -1", "if (a) {\n    let b = 1;\n};\n-1;\n"},
+		{"if (a) { let b = 1; }; c", "if (a) {\n    let b = 1;\n}\nc;\n"},
+		{"export let x = import \"lib/math\";", "export let x = import \"lib/math\";\n"},
+		{`let h = {"a": 1, "b": [1, 2]}`, "let h = {\"a\": 1, \"b\": [1, 2]};\n"},
+		{"let a = 1;\n\n\n\nlet b = 2;\nlet c = 3;", "let a = 1;\n\nlet b = 2;\nlet c = 3;\n"},
+		{"let n:int=1\nlet f = fn(a:[int],b) ->{string:fn(int)->bool} { a }",
+			"let n: int = 1;\nlet f = fn(a: [int], b) -> {string: fn(int) -> bool} { a };\n"},
+		{"let m = macro(a,b) { quote(unquote(a) + unquote(b)) }",
+			"let m = macro(a, b) { quote(unquote(a) + unquote(b)) };\n"},
+		{"#!/usr/bin/env monkey\nlet a=1", "#!/usr/bin/env monkey\nlet a = 1;\n"},
+	} {
+		formatted, err := Source(tt.input)
+		if err != nil {
+			t.Errorf("Source(%q) failed: %s", tt.input, err)
+			continue
+		}
+		if formatted != tt.expected {
+			t.Errorf("Source(%q) wrong.\nexpected:\n%s\ngot:\n%s", tt.input, tt.expected, formatted)
+		}
+	}
+}
+
+func TestLineBreaking(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected string
+	}{
+		{
+			`let h = {"one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6, "seven": 7};`,
+			`let h = {
+    "one": 1,
+    "two": 2,
+    "three": 3,
+    "four": 4,
+    "five": 5,
+    "six": 6,
+    "seven": 7
+};
+`,
+		},
+		{
+			`map([1, 2, 3], fn(x) { let y = x * 2; y + 1 });`,
+			`map([1, 2, 3], fn(x) {
+    let y = x * 2;
+    y + 1;
+});
+`,
+		},
+		{
+			`let total = reduce(numbers, fn(acc, n) { acc + n * n * n * n * n * n * n * n * n }, 0);`,
+			`let total = reduce(
+    numbers,
+    fn(acc, n) { acc + n * n * n * n * n * n * n * n * n },
+    0
+);
+`,
+		},
+		{
+			`let f = fn() { let xs = [aaaaaaaaaaaaaaaa, bbbbbbbbbbbbbbbbbbb, cccccccccccccccccc, ddddddddd]; xs };`,
+			`let f = fn() {
+    let xs = [
+        aaaaaaaaaaaaaaaa,
+        bbbbbbbbbbbbbbbbbbb,
+        cccccccccccccccccc,
+        ddddddddd
+    ];
+    xs;
+};
+`,
+		},
+	} {
+		formatted, err := Source(tt.input)
+		if err != nil {
+			t.Errorf("Source(%q) failed: %s", tt.input, err)
+			continue
+		}
+		if formatted != tt.expected {
+			t.Errorf("Source(%q) wrong.\nexpected:\n%s\ngot:\n%s", tt.input, tt.expected, formatted)
+		}
+		for _, line := range strings.Split(formatted, "\n") {
+			if len(line) > WIDTH {
+				t.Errorf("line longer than %d columns: %q", WIDTH, line)
+			}
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected string
+	}{
+		{"// only a comment", "// only a comment\n"},
+		{
+			"// Greeting.\nlet greet = fn(name) { \"hi \" + name }; // short   \n// end",
+			"// Greeting.\nlet greet = fn(name) { \"hi \" + name }; // short\n// end\n",
+		},
+		{
+			"let add = fn(a, b) { // sum\n  // of both\n  return a + b;\n\n  // done\n}",
+			"let add = fn(a, b) {\n    // sum\n    // of both\n    return a + b;\n\n    // done\n};\n",
+		},
+		{
+			"let f = fn() {\n// nothing\n};",
+			"let f = fn() {\n    // nothing\n};\n",
+		},
+		{
+			"let f = fn(x) { x // value\n};",
+			"let f = fn(x) {\n    x; // value\n};\n",
+		},
+		{
+			"let a = [1, // one\n  2];\nlet b = 2;",
+			"let a = [1, 2];\n// one\nlet b = 2;\n",
+		},
+		{
+			"if (a) {\n  b\n} // after\nc",
+			"if (a) { b } // after\nc;\n",
+		},
+	} {
+		formatted, err := Source(tt.input)
+		if err != nil {
+			t.Errorf("Source(%q) failed: %s", tt.input, err)
+			continue
+		}
+		if formatted != tt.expected {
+			t.Errorf("Source(%q) wrong.\nexpected:\n%s\ngot:\n%s", tt.input, tt.expected, formatted)
+		}
+	}
+}
+
+// TestRoundTrip checks that formatting keeps the meaning of programs and
+// their comments, and that formatting formatted code changes nothing.
+func TestRoundTrip(t *testing.T) {
+	for _, input := range []string{
+		`let fib = fn(n) { if (n < 2) { return n; } fib(n - 1) + fib(n - 2) }; fib(10)`,
+		`let m = {"k": fn(x) { x }, 1: [true, false, "s"]}; m["k"](m[1][0])`,
+		`let xs = map(range(10), fn(x) { if (x / 2 * 2 == x) { x } else { -x } });`,
+		`let long = fn(aaaa, bbbb, cccc) { aaaa(bbbb(cccc(aaaa, bbbb, cccc), cccc, bbbb), aaaa) };`,
+		"// header\n\nlet a = 1; // one\n\n\n// two\nlet b = fn() {\n  // inside\n  a\n  // last\n};\n// footer",
+		`if (a) { b } else { c }; (d)(e); if (f) { g }; [h]; if (i) { j }; -k`,
+		"let h = {\n  \"a\": 1\n};\nlet b = [\n  1\n];\nf(\n  1\n)[\n  0\n];\n// end",
+		`let nested = [[1, [2, [3, {"a": [4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17]}]]]];`,
+	} {
+		formatted, err := Source(input)
+		if err != nil {
+			t.Errorf("Source(%q) failed: %s", input, err)
+			continue
+		}
+
+		original, comments := parse(t, input)
+		result, resultComments := parse(t, formatted)
+		if original != result {
+			t.Errorf("formatting changed the program.\ninput:\n%s\nformatted:\n%s", input, formatted)
+		}
+		if comments != resultComments {
+			t.Errorf("formatting lost comments.\nexpected %q, got %q", comments, resultComments)
+		}
+
+		again, err := Source(formatted)
+		if err != nil {
+			t.Errorf("Source(%q) failed: %s", formatted, err)
+			continue
+		}
+		if again != formatted {
+			t.Errorf("formatting is not idempotent.\nfirst:\n%s\nsecond:\n%s", formatted, again)
+		}
+	}
+}
+
+func TestNode(t *testing.T) {
+	program := parser.New(lexer.New("let f = fn(x) { let y = x; (y + 1) * 2 };")).ParseProgram()
+	let := program.Statements[0].(*ast.LetStatement)
+	fn := let.Value.(*ast.FunctionLiteral)
+
+	for _, tt := range []struct {
+		node     ast.Node
+		expected string
+	}{
+		{program, "let f = fn(x) {\n    let y = x;\n    (y + 1) * 2;\n};\n"},
+		{let, "let f = fn(x) {\n    let y = x;\n    (y + 1) * 2;\n};"},
+		{fn.Body.Statements[0], "let y = x;"},
+		{fn.Body.Statements[1], "(y + 1) * 2;"},
+		{fn.Body.Statements[1].(*ast.ExpressionStatement).Expression, "(y + 1) * 2"},
+		{fn.Body, "{\n    let y = x;\n    (y + 1) * 2;\n}"},
+	} {
+		if got := Node(tt.node); got != tt.expected {
+			t.Errorf("Node(%T) wrong. want=%q, got=%q", tt.node, tt.expected, got)
+		}
+	}
+}
+
+func TestSyntaxError(t *testing.T) {
+	_, err := Source("let = 1;\nlet x 2;")
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	syntaxErr, ok := err.(*SyntaxError)
+	if !ok {
+		t.Fatalf("error is not *SyntaxError. got=%T", err)
+	}
+	if len(syntaxErr.Errors) == 0 || syntaxErr.Errors[0] != "1:5: Expected token: 'IDENT'. Got '='." {
+		t.Errorf("unexpected errors: %q", syntaxErr.Errors)
+	}
+}
+
+// parse returns the canonical form of the program in src, along with its
+// comments joined together.
+func parse(t *testing.T, src string) (string, string) {
+	t.Helper()
+
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("could not parse %q: %s", src, p.Errors())
+	}
+
+	comments := []string{}
+	for _, c := range program.Comments {
+		comments = append(comments, c.String())
+	}
+	return program.String(), strings.Join(comments, "\n")
+}
@@ -0,0 +1,495 @@
+package format
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"monkey/ast"
+	"monkey/token"
+)
+
+// Precedences of expressions, deciding where parentheses are needed. They
+// follow those of the parser.
+const (
+	_ int = iota
+	precEquals
+	precCompare
+	precSum
+	precProduct
+	precPrefix
+	precCall
+	precAtom
+)
+
+var infixPrecedences = map[string]int{
+	"==": precEquals,
+	"!=": precEquals,
+	"<":  precCompare,
+	">":  precCompare,
+	"+":  precSum,
+	"-":  precSum,
+	"*":  precProduct,
+	"/":  precProduct,
+}
+
+// printer writes formatted source to out. A printer in flat mode renders
+// everything on a single line, and gives up on blocks that cannot be.
+type printer struct {
+	out   strings.Builder
+	depth int
+	// col0 is the column out starts at.
+	col0 int
+	// comments holds the comments not written yet, in source order.
+	comments []*ast.Comment
+
+	flat   bool
+	failed bool
+}
+
+// fork returns a printer continuing from the current position of p, whose
+// output is only kept if given back to adopt.
+func (p *printer) fork(flat bool) *printer {
+	return &printer{
+		depth:    p.depth,
+		col0:     p.col(),
+		comments: p.comments,
+		flat:     p.flat || flat,
+	}
+}
+
+func (p *printer) adopt(q *printer) {
+	p.out.WriteString(q.out.String())
+	p.comments = q.comments
+}
+
+func (p *printer) write(s string) {
+	p.out.WriteString(s)
+}
+
+func (p *printer) indent() {
+	p.write(strings.Repeat(INDENT, p.depth))
+}
+
+func (p *printer) col() int {
+	s := p.out.String()
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		return utf8.RuneCountInString(s[i+1:])
+	}
+	return p.col0 + utf8.RuneCountInString(s)
+}
+
+// fits reports whether the first line of s fits on the current line.
+func (p *printer) fits(s string) bool {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return p.col()+utf8.RuneCountInString(s) <= WIDTH
+}
+
+// commentBefore reports whether the next comment to write comes before tok.
+func (p *printer) commentBefore(tok token.Token) bool {
+	if len(p.comments) == 0 {
+		return false
+	}
+	c := p.comments[0].Token
+	return c.Line < tok.Line || c.Line == tok.Line && c.Column < tok.Column
+}
+
+func (p *printer) popComment() *ast.Comment {
+	c := p.comments[0]
+	p.comments = p.comments[1:]
+
+	return c
+}
+
+// statements writes stmts one per line, along with the comments found before
+// end, or all remaining comments if end is nil. A single blank line is kept
+// wherever the source had any.
+func (p *printer) statements(stmts []ast.Statement, end *token.Token) {
+	prevLine := 0
+	separate := func(line int) {
+		if prevLine > 0 && line > prevLine+1 {
+			p.write("\n")
+		}
+	}
+	writeComment := func(c *ast.Comment) {
+		separate(c.Token.Line)
+		p.indent()
+		p.write(c.Token.Literal + "\n")
+		if c.Token.Line > prevLine {
+			prevLine = c.Token.Line
+		}
+	}
+
+	for i, stmt := range stmts {
+		start := ast.StartToken(stmt)
+		for p.commentBefore(start) {
+			writeComment(p.popComment())
+		}
+		separate(start.Line)
+
+		p.indent()
+		p.statement(stmt)
+		if expr, ok := stmt.(*ast.ExpressionStatement); ok {
+			_, isIf := expr.Expression.(*ast.IfExpression)
+			if !isIf || i+1 < len(stmts) && continues(stmts[i+1]) {
+				p.write(";")
+			}
+		}
+
+		last := endLine(stmt)
+		if len(p.comments) > 0 && p.comments[0].Token.Line == last {
+			p.write(" " + p.popComment().Token.Literal)
+		}
+		p.write("\n")
+		prevLine = last
+	}
+
+	for len(p.comments) > 0 && (end == nil || p.commentBefore(*end)) {
+		writeComment(p.popComment())
+	}
+}
+
+// statement writes stmt, without the semicolon ending expression statements.
+func (p *printer) statement(stmt ast.Statement) {
+	switch stmt := stmt.(type) {
+	case *ast.LetStatement:
+		p.write("let " + stmt.Name.Value)
+		if stmt.Type != nil {
+			p.write(": " + stmt.Type.String())
+		}
+		p.write(" = ")
+		p.expr(stmt.Value)
+		p.write(";")
+	case *ast.ReturnStatement:
+		p.write("return ")
+		p.expr(stmt.ReturnValue)
+		p.write(";")
+	case *ast.ExportStatement:
+		p.write("export ")
+		p.statement(stmt.Statement)
+	case *ast.ExpressionStatement:
+		p.expr(stmt.Expression)
+	}
+}
+
+// expr writes e on the current line if it fits, and breaks it across lines
+// otherwise.
+func (p *printer) expr(e ast.Expression) {
+	if p.flat {
+		p.node(e)
+		return
+	}
+
+	q := p.fork(true)
+	q.node(e)
+	if !q.failed && p.fits(q.out.String()) {
+		p.adopt(q)
+		return
+	}
+	p.node(e)
+}
+
+func (p *printer) node(e ast.Expression) {
+	switch e := e.(type) {
+	case *ast.Identifier:
+		p.write(e.Value)
+	case *ast.IntegerLiteral:
+		p.write(e.Token.Literal)
+	case *ast.Boolean:
+		p.write(e.Token.Literal)
+	case *ast.StringLiteral:
+		p.write(`"` + e.Value + `"`)
+	case *ast.ImportExpression:
+		p.write(`import "` + e.Path.Value + `"`)
+	case *ast.PrefixExpression:
+		p.write(e.Operator)
+		p.operand(e.Right, precPrefix)
+	case *ast.InfixExpression:
+		prec := infixPrecedences[e.Operator]
+		p.operand(e.Left, prec)
+		p.write(" " + e.Operator + " ")
+		p.operand(e.Right, prec+1)
+	case *ast.CallExpression:
+		p.operand(e.Function, precCall)
+		p.list("(", exprItems(e.Arguments), ")")
+	case *ast.IndexExpression:
+		p.operand(e.Left, precCall)
+		p.write("[")
+		p.expr(e.Index)
+		p.write("]")
+	case *ast.ArrayLiteral:
+		p.list("[", exprItems(e.Elements), "]")
+	case *ast.HashLiteral:
+		pairs := make([]item, len(e.Keys))
+		for i, key := range e.Keys {
+			key, value := key, e.Pairs[key]
+			pairs[i] = func(p *printer) {
+				p.expr(key)
+				p.write(": ")
+				p.expr(value)
+			}
+		}
+		p.list("{", pairs, "}")
+	case *ast.FunctionLiteral:
+		p.write("fn(" + parameters(e.Parameters, e.ParameterTypes) + ") ")
+		if e.ReturnType != nil {
+			p.write("-> " + e.ReturnType.String() + " ")
+		}
+		p.block(e.Body)
+	case *ast.MacroLiteral:
+		p.write("macro(" + parameters(e.Parameters, nil) + ") ")
+		p.block(e.Body)
+	case *ast.IfExpression:
+		p.write("if (")
+		p.expr(e.Condition)
+		p.write(") ")
+		p.block(e.Consequence)
+		if e.Alternative != nil {
+			p.write(" else ")
+			p.block(e.Alternative)
+		}
+	}
+}
+
+// item writes an element of a list, such as an argument or a hash pair.
+type item func(p *printer)
+
+// parameters lists params, each followed by its type in types if it has
+// one.
+func parameters(params []*ast.Identifier, types []ast.TypeExpr) string {
+	names := make([]string, len(params))
+	for i, param := range params {
+		names[i] = param.Value
+		if i < len(types) && types[i] != nil {
+			names[i] += ": " + types[i].String()
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+func exprItems(exprs []ast.Expression) []item {
+	items := make([]item, len(exprs))
+	for i, e := range exprs {
+		e := e
+		items[i] = func(p *printer) { p.expr(e) }
+	}
+	return items
+}
+
+// operand writes e within parentheses if its precedence is lower than prec.
+func (p *printer) operand(e ast.Expression, prec int) {
+	if precedence(e) >= prec {
+		p.expr(e)
+		return
+	}
+	p.write("(")
+	p.expr(e)
+	p.write(")")
+}
+
+// list writes items between open and close, all on the current line if they
+// fit. Otherwise, the last item may be broken across lines if the others
+// still fit, and failing that, each item goes on its own line.
+func (p *printer) list(open string, items []item, close string) {
+	if p.flat || len(items) == 0 {
+		p.write(open)
+		for i, item := range items {
+			if i > 0 {
+				p.write(", ")
+			}
+			item(p)
+		}
+		p.write(close)
+		return
+	}
+
+	q := p.fork(false)
+	q.write(open)
+	for _, item := range items[:len(items)-1] {
+		f := q.fork(true)
+		item(f)
+		if f.failed {
+			q.failed = true
+			break
+		}
+		q.adopt(f)
+		q.write(", ")
+	}
+	if !q.failed && q.col() <= WIDTH {
+		items[len(items)-1](q)
+		q.write(close)
+		if p.fits(q.out.String()) {
+			p.adopt(q)
+			return
+		}
+	}
+
+	p.write(open + "\n")
+	p.depth++
+	for i, item := range items {
+		p.indent()
+		item(p)
+		if i < len(items)-1 {
+			p.write(",")
+		}
+		p.write("\n")
+	}
+	p.depth--
+	p.indent()
+	p.write(close)
+}
+
+// block writes b with one statement per line. In flat mode, only empty
+// blocks and those holding a single expression can be written.
+func (p *printer) block(b *ast.BlockStatement) {
+	inline := !p.commentBefore(b.Rbrace)
+
+	if p.flat {
+		switch {
+		case inline && len(b.Statements) == 0:
+			p.write("{}")
+		case inline && isInlineStatement(b.Statements[0]) && len(b.Statements) == 1:
+			p.write("{ ")
+			p.statement(b.Statements[0])
+			p.write(" }")
+		default:
+			p.failed = true
+		}
+		return
+	}
+
+	if inline && len(b.Statements) == 0 {
+		p.write("{}")
+		return
+	}
+	p.write("{\n")
+	p.depth++
+	p.statements(b.Statements, &b.Rbrace)
+	p.depth--
+	p.indent()
+	p.write("}")
+}
+
+func isInlineStatement(stmt ast.Statement) bool {
+	expr, ok := stmt.(*ast.ExpressionStatement)
+	if !ok {
+		return false
+	}
+	_, isIf := expr.Expression.(*ast.IfExpression)
+
+	return !isIf
+}
+
+func precedence(e ast.Expression) int {
+	switch e := e.(type) {
+	case *ast.InfixExpression:
+		return infixPrecedences[e.Operator]
+	case *ast.PrefixExpression:
+		return precPrefix
+	case *ast.CallExpression, *ast.IndexExpression:
+		return precCall
+	default:
+		return precAtom
+	}
+}
+
+// continues reports whether stmt, once formatted, starts with a token that
+// would otherwise continue an `if` expression written before it.
+func continues(stmt ast.Statement) bool {
+	expr, ok := stmt.(*ast.ExpressionStatement)
+	if !ok {
+		return false
+	}
+
+	e := expr.Expression
+	for {
+		switch inner := e.(type) {
+		case *ast.InfixExpression:
+			if precedence(inner.Left) < infixPrecedences[inner.Operator] {
+				return true
+			}
+			e = inner.Left
+		case *ast.CallExpression:
+			if precedence(inner.Function) < precCall {
+				return true
+			}
+			e = inner.Function
+		case *ast.IndexExpression:
+			if precedence(inner.Left) < precCall {
+				return true
+			}
+			e = inner.Left
+		case *ast.PrefixExpression:
+			return inner.Operator == "-"
+		case *ast.ArrayLiteral:
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// endLine returns the last line of the source holding part of node, as far
+// as can be told from the positions kept in the tree.
+func endLine(node ast.Node) int {
+	line := 0
+	see := func(n ast.Node) {
+		if l := endLine(n); l > line {
+			line = l
+		}
+	}
+	seeToken := func(tok token.Token) {
+		if tok.Line > line {
+			line = tok.Line
+		}
+	}
+
+	switch n := node.(type) {
+	case *ast.LetStatement:
+		seeToken(n.Token)
+		see(n.Value)
+	case *ast.ReturnStatement:
+		seeToken(n.Token)
+		see(n.ReturnValue)
+	case *ast.ExportStatement:
+		see(n.Statement)
+	case *ast.ExpressionStatement:
+		seeToken(n.Token)
+		see(n.Expression)
+	case *ast.BlockStatement:
+		seeToken(n.Rbrace)
+	case *ast.Identifier:
+		seeToken(n.Token)
+	case *ast.IntegerLiteral:
+		seeToken(n.Token)
+	case *ast.Boolean:
+		seeToken(n.Token)
+	case *ast.StringLiteral:
+		seeToken(n.Token)
+	case *ast.ImportExpression:
+		seeToken(n.Path.Token)
+	case *ast.PrefixExpression:
+		see(n.Right)
+	case *ast.InfixExpression:
+		see(n.Right)
+	case *ast.CallExpression:
+		seeToken(n.Rparen)
+	case *ast.IndexExpression:
+		seeToken(n.Rbracket)
+	case *ast.ArrayLiteral:
+		seeToken(n.Rbracket)
+	case *ast.HashLiteral:
+		seeToken(n.Rbrace)
+	case *ast.FunctionLiteral:
+		see(n.Body)
+	case *ast.MacroLiteral:
+		see(n.Body)
+	case *ast.IfExpression:
+		see(n.Consequence)
+		if n.Alternative != nil {
+			see(n.Alternative)
+		}
+	}
+	return line
+}
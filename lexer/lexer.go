@@ -1,16 +1,24 @@
 package lexer
 
-import "monkey/token"
+import (
+	"strings"
+
+	"monkey/token"
+)
 
 type Lexer struct {
 	input        string
 	position     int
 	readPosition int
 	ch           byte
+	line         int
+	column       int
+	// comments holds the comments skipped so far, in source order.
+	comments []token.Token
 }
 
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	l := &Lexer{input: input, line: 1}
 	l.readChar()
 
 	return l
@@ -21,6 +29,8 @@ func (l *Lexer) NextToken() token.Token {
 
 	l.skipWhitespace()
 
+	line, column := l.line, l.column
+
 	switch l.ch {
 	case '=':
 		if l.peekChar() == '=' {
@@ -33,7 +43,13 @@ func (l *Lexer) NextToken() token.Token {
 	case '+':
 		tok = newToken(token.PLUS, l.ch)
 	case '-':
-		tok = newToken(token.MINUS, l.ch)
+		if l.peekChar() == '>' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.ARROW, Literal: string(ch) + string(l.ch)}
+		} else {
+			tok = newToken(token.MINUS, l.ch)
+		}
 	case '!':
 		if l.peekChar() == '=' {
 			ch := l.ch
@@ -67,8 +83,16 @@ func (l *Lexer) NextToken() token.Token {
 	case ']':
 		tok = newToken(token.RBRACKET, l.ch)
 	case '"':
-		tok.Type = token.STRING
-		tok.Literal = l.readString()
+		str, ok := l.readString()
+		if ok {
+			tok.Type = token.STRING
+			tok.Literal = str
+		} else {
+			// Keep the opening quote, so that callers can tell an
+			// unterminated string apart from other illegal input.
+			tok.Type = token.ILLEGAL
+			tok.Literal = `"` + str
+		}
 	case ':':
 		tok = newToken(token.COLON, l.ch)
 	case 0:
@@ -78,10 +102,12 @@ func (l *Lexer) NextToken() token.Token {
 		if isLetter(l.ch) {
 			tok.Literal = l.readIdentifier()
 			tok.Type = token.LookupIdent(tok.Literal)
+			tok.Line, tok.Column = line, column
 			return tok
 		} else if isDigit(l.ch) {
 			tok.Type = token.INT
 			tok.Literal = l.readNumber()
+			tok.Line, tok.Column = line, column
 			return tok
 		} else {
 			tok = newToken(token.ILLEGAL, l.ch)
@@ -89,6 +115,8 @@ func (l *Lexer) NextToken() token.Token {
 	}
 	l.readChar()
 
+	tok.Line, tok.Column = line, column
+
 	return tok
 }
 
@@ -105,6 +133,10 @@ func newToken(tokenType token.TokenType, ch byte) token.Token {
 }
 
 func (l *Lexer) readChar() {
+	if l.ch == '\n' {
+		l.line += 1
+		l.column = 0
+	}
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
 	} else {
@@ -112,6 +144,7 @@ func (l *Lexer) readChar() {
 	}
 	l.position = l.readPosition
 	l.readPosition += 1
+	l.column += 1
 }
 
 func (l *Lexer) readNumber() string {
@@ -130,7 +163,9 @@ func (l *Lexer) readIdentifier() string {
 	return l.input[position:l.position]
 }
 
-func (l *Lexer) readString() string {
+// readString reads a string literal up to its closing quote, reporting
+// whether the quote was found before the end of the input.
+func (l *Lexer) readString() (string, bool) {
 	position := l.position + 1
 	for {
 		l.readChar()
@@ -138,7 +173,7 @@ func (l *Lexer) readString() string {
 			break
 		}
 	}
-	return l.input[position:l.position]
+	return l.input[position:l.position], l.ch == '"'
 }
 
 func isLetter(ch byte) bool {
@@ -151,8 +186,35 @@ func isDigit(ch byte) bool {
 	return '0' <= ch && ch <= '9'
 }
 
+// Comments returns the comments found in the input read so far. They are
+// not returned by NextToken, as they may appear between any two tokens.
+func (l *Lexer) Comments() []token.Token {
+	return l.comments
+}
+
+// skipWhitespace skips blanks and comments, which go from '//' to the end of
+// the line.
 func (l *Lexer) skipWhitespace() {
-	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
+	for {
+		switch {
+		case l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r':
+			l.readChar()
+		case l.ch == '/' && l.peekChar() == '/':
+			l.comments = append(l.comments, l.readComment())
+		default:
+			return
+		}
+	}
+}
+
+func (l *Lexer) readComment() token.Token {
+	tok := token.Token{Type: token.COMMENT, Line: l.line, Column: l.column}
+
+	position := l.position
+	for l.ch != '\n' && l.ch != 0 {
 		l.readChar()
 	}
+	tok.Literal = strings.TrimRight(l.input[position:l.position], " \t\r")
+
+	return tok
 }
@@ -34,6 +34,7 @@ if (5 < 10) {
 "foo bar"
 [1, 2];
 {"foo": "bar"}
+fn() -> int
 `
 	l := New(input)
 
@@ -126,6 +127,11 @@ if (5 < 10) {
 		{token.COLON, ":"},
 		{token.STRING, "bar"},
 		{token.RBRACE, "}"},
+		{token.FUNCTION, "fn"},
+		{token.LPAREN, "("},
+		{token.RPAREN, ")"},
+		{token.ARROW, "->"},
+		{token.IDENT, "int"},
 		{token.EOF, ""},
 	} {
 		tok := l.NextToken()
@@ -141,3 +147,99 @@ if (5 < 10) {
 		}
 	}
 }
+
+func TestTokenPositions(t *testing.T) {
+	input := `let x = 5;
+if (x) {
+	"a b" + x
+}`
+	l := New(input)
+
+	for i, tt := range []struct {
+		expectedLiteral string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{"let", 1, 1},
+		{"x", 1, 5},
+		{"=", 1, 7},
+		{"5", 1, 9},
+		{";", 1, 10},
+		{"if", 2, 1},
+		{"(", 2, 4},
+		{"x", 2, 5},
+		{")", 2, 6},
+		{"{", 2, 8},
+		{"a b", 3, 2},
+		{"+", 3, 8},
+		{"x", 3, 10},
+		{"}", 4, 1},
+		{"", 4, 2},
+	} {
+		tok := l.NextToken()
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d]: expected %q, got %q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Line != tt.expectedLine || tok.Column != tt.expectedColumn {
+			t.Fatalf("tests[%d]: expected %d:%d, got %d:%d", i,
+				tt.expectedLine, tt.expectedColumn, tok.Line, tok.Column)
+		}
+	}
+}
+
+func TestUnterminatedString(t *testing.T) {
+	l := New(`let s = "foo`)
+
+	for _, expected := range []token.Token{
+		{Type: token.LET, Literal: "let"},
+		{Type: token.IDENT, Literal: "s"},
+		{Type: token.ASSIGN, Literal: "="},
+		{Type: token.ILLEGAL, Literal: `"foo`},
+		{Type: token.EOF, Literal: ""},
+	} {
+		tok := l.NextToken()
+
+		if tok.Type != expected.Type || tok.Literal != expected.Literal {
+			t.Fatalf("expected %s %q, got %s %q",
+				expected.Type, expected.Literal, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	l := New("// header\nlet x = 10 / 2; // half  \n//\n")
+
+	for _, expected := range []token.Token{
+		{Type: token.LET, Literal: "let"},
+		{Type: token.IDENT, Literal: "x"},
+		{Type: token.ASSIGN, Literal: "="},
+		{Type: token.INT, Literal: "10"},
+		{Type: token.SLASH, Literal: "/"},
+		{Type: token.INT, Literal: "2"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.EOF, Literal: ""},
+	} {
+		tok := l.NextToken()
+
+		if tok.Type != expected.Type || tok.Literal != expected.Literal {
+			t.Fatalf("expected %s %q, got %s %q",
+				expected.Type, expected.Literal, tok.Type, tok.Literal)
+		}
+	}
+
+	comments := l.Comments()
+	for i, expected := range []token.Token{
+		{Type: token.COMMENT, Literal: "// header", Line: 1, Column: 1},
+		{Type: token.COMMENT, Literal: "// half", Line: 2, Column: 17},
+		{Type: token.COMMENT, Literal: "//", Line: 3, Column: 1},
+	} {
+		if i >= len(comments) {
+			t.Fatalf("expected %d comments, got %d", i+1, len(comments))
+		}
+		if comments[i] != expected {
+			t.Errorf("comment %d wrong. expected %+v, got %+v", i, expected, comments[i])
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"monkey/ast"
+	"monkey/token"
+)
+
+type binding struct {
+	name  *ast.Identifier
+	param bool
+	used  bool
+}
+
+// scope holds the bindings of a function, or of the program. Blocks of if
+// expressions share the scope they appear in, as they do when evaluated.
+type scope struct {
+	parent   *scope
+	bindings map[string]*binding
+	declared []*binding
+	// pending holds the functions defined in the scope. Their bodies are
+	// checked once the whole scope is, since they may call functions bound
+	// after them.
+	pending []func()
+}
+
+func newScope(parent *scope) *scope {
+	return &scope{parent: parent, bindings: make(map[string]*binding)}
+}
+
+func (s *scope) lookup(name string) *binding {
+	for ; s != nil; s = s.parent {
+		if b, ok := s.bindings[name]; ok {
+			return b
+		}
+	}
+	return nil
+}
+
+type checker struct {
+	*Linter
+	diagnostics []Diagnostic
+}
+
+func (c *checker) report(check Check, tok token.Token, format string, a ...interface{}) {
+	if c.checks&check == 0 {
+		return
+	}
+	c.diagnostics = append(c.diagnostics, Diagnostic{
+		Line:    tok.Line,
+		Column:  tok.Column,
+		Check:   check,
+		Message: fmt.Sprintf(format, a...),
+	})
+}
+
+// close checks the functions defined in s, and then reports the bindings of
+// s that were never used.
+func (c *checker) close(s *scope) {
+	for i := 0; i < len(s.pending); i++ {
+		s.pending[i]()
+	}
+	for _, b := range s.declared {
+		if !b.used && !b.param && !strings.HasPrefix(b.name.Value, "_") {
+			c.report(Unused, b.name.Token, "%s is never used", b.name.Value)
+		}
+	}
+}
+
+func (c *checker) declare(s *scope, name *ast.Identifier, param bool) {
+	if outer := s.parent.lookup(name.Value); outer != nil {
+		c.report(Shadow, name.Token, "%s shadows the binding on line %d",
+			name.Value, outer.name.Token.Line)
+	} else if c.globals[name.Value] {
+		c.report(Shadow, name.Token, "%s shadows a builtin", name.Value)
+	}
+
+	b := &binding{name: name, param: param}
+	s.bindings[name.Value] = b
+	s.declared = append(s.declared, b)
+}
+
+func (c *checker) use(s *scope, ident *ast.Identifier) {
+	if b := s.lookup(ident.Value); b != nil {
+		b.used = true
+	} else if !c.globals[ident.Value] {
+		c.report(Undefined, ident.Token, "undefined identifier: %s", ident.Value)
+	}
+}
+
+func (c *checker) statements(s *scope, stmts []ast.Statement) {
+	terminated, reported := false, false
+
+	for _, stmt := range stmts {
+		if stmt == nil {
+			continue
+		}
+		// Only the first unreachable statement is reported, but the others
+		// are still checked.
+		if terminated && !reported {
+			c.report(Unreachable, ast.StartToken(stmt), "unreachable code")
+			reported = true
+		}
+		c.check(s, stmt)
+		terminated = terminated || terminates(stmt)
+	}
+}
+
+// check checks node and everything within it in scope s. Nodes making
+// bindings, or evaluated elsewhere than where they appear, are handled
+// here, and the others are left for Inspect to go through.
+func (c *checker) check(s *scope, node ast.Node) {
+	ast.Inspect(node, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.Identifier:
+			c.use(s, n)
+		case *ast.LetStatement:
+			c.check(s, n.Value)
+			c.declare(s, n.Name, false)
+			return false
+		case *ast.ExportStatement:
+			c.check(s, n.Statement)
+			if b := s.bindings[n.Statement.Name.Value]; b != nil {
+				b.used = true
+			}
+			return false
+		case *ast.BlockStatement:
+			c.statements(s, n.Statements)
+			return false
+		case *ast.FunctionLiteral:
+			c.function(s, n.Parameters, n.Body)
+			return false
+		case *ast.MacroLiteral:
+			c.function(s, n.Parameters, n.Body)
+			return false
+		case *ast.CallExpression:
+			if ident, ok := n.Function.(*ast.Identifier); ok && ident.Value == "quote" {
+				c.quote(s, n.Arguments)
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func (c *checker) function(s *scope, params []*ast.Identifier, body *ast.BlockStatement) {
+	s.pending = append(s.pending, func() {
+		inner := newScope(s)
+		for _, param := range params {
+			c.declare(inner, param, true)
+		}
+		c.statements(inner, body.Statements)
+		c.close(inner)
+	})
+}
+
+// quote checks the arguments of calls to `unquote` within quoted code, which
+// are the only parts of it evaluated where it appears.
+func (c *checker) quote(s *scope, args []ast.Expression) {
+	for _, arg := range args {
+		ast.Inspect(arg, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpression)
+			if !ok {
+				return true
+			}
+			if ident, ok := call.Function.(*ast.Identifier); ok && ident.Value == "unquote" {
+				for _, arg := range call.Arguments {
+					c.check(s, arg)
+				}
+				return false
+			}
+			return true
+		})
+	}
+}
+
+// terminates reports whether the statements following stmt can never run,
+// because it returns on every path.
+func terminates(stmt ast.Statement) bool {
+	switch stmt := stmt.(type) {
+	case *ast.ReturnStatement:
+		return true
+	case *ast.ExpressionStatement:
+		ie, ok := stmt.Expression.(*ast.IfExpression)
+		if !ok || ie.Alternative == nil {
+			return false
+		}
+		return blockTerminates(ie.Consequence) && blockTerminates(ie.Alternative)
+	default:
+		return false
+	}
+}
+
+func blockTerminates(b *ast.BlockStatement) bool {
+	for _, stmt := range b.Statements {
+		if stmt != nil && terminates(stmt) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+// Package lint reports likely mistakes in Monkey programs without running
+// them, such as bindings that are never used or code that can never run.
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"monkey/ast"
+)
+
+// Check is a set of the checks a linter runs.
+type Check uint
+
+const (
+	// Unused reports let bindings that are never referred to. Exported
+	// bindings and names starting with an underscore are left alone.
+	Unused Check = 1 << iota
+	// Shadow reports bindings hiding one from an enclosing function, or a
+	// builtin.
+	Shadow
+	// Undefined reports identifiers that are bound nowhere.
+	Undefined
+	// Unreachable reports statements following a return.
+	Unreachable
+
+	NoChecks  Check = 0
+	AllChecks       = Unused | Shadow | Undefined | Unreachable
+)
+
+var checkNames = []struct {
+	check Check
+	name  string
+}{
+	{Unused, "unused"},
+	{Shadow, "shadow"},
+	{Undefined, "undefined"},
+	{Unreachable, "unreachable"},
+}
+
+func (c Check) String() string {
+	names := []string{}
+	for _, cn := range checkNames {
+		if c&cn.check != 0 {
+			names = append(names, cn.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ",")
+}
+
+func (c Check) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// ParseChecks returns the checks named in s, separated by commas. The name
+// "all" stands for every check.
+func ParseChecks(s string) (Check, error) {
+	checks := NoChecks
+
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "all" {
+			checks |= AllChecks
+			continue
+		}
+		found := false
+		for _, cn := range checkNames {
+			if cn.name == name {
+				checks |= cn.check
+				found = true
+			}
+		}
+		if !found {
+			return NoChecks, fmt.Errorf("unknown check %q", name)
+		}
+	}
+	return checks, nil
+}
+
+// Diagnostic is a problem found by one of the checks.
+type Diagnostic struct {
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Check   Check  `json:"check"`
+	Message string `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%d:%d: %s (%s)", d.Line, d.Column, d.Message, d.Check)
+}
+
+type Option func(*Linter)
+
+// Linter runs a set of checks over programs.
+type Linter struct {
+	checks Check
+	// globals are the names bound before a program starts, such as those
+	// of builtins.
+	globals map[string]bool
+}
+
+// New returns a linter running every check, unless told otherwise.
+func New(opts ...Option) *Linter {
+	l := &Linter{checks: AllChecks, globals: make(map[string]bool)}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// WithChecks makes the linter only run the given checks.
+func WithChecks(checks Check) Option {
+	return func(l *Linter) {
+		l.checks = checks
+	}
+}
+
+// WithGlobals declares names that programs may use without binding them,
+// usually those of the builtins.
+func WithGlobals(names ...string) Option {
+	return func(l *Linter) {
+		for _, name := range names {
+			l.globals[name] = true
+		}
+	}
+}
+
+// Program returns the problems found in program, ordered by position.
+func (l *Linter) Program(program *ast.Program) []Diagnostic {
+	c := &checker{Linter: l}
+
+	top := newScope(nil)
+	c.statements(top, program.Statements)
+	c.close(top)
+
+	sort.SliceStable(c.diagnostics, func(i, j int) bool {
+		a, b := c.diagnostics[i], c.diagnostics[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return c.diagnostics
+}
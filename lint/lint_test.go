@@ -0,0 +1,138 @@
+package lint
+
+import (
+	"encoding/json"
+	"testing"
+
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/parser"
+)
+
+func TestLint(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected []string
+	}{
+		{"let x = 1; x", []string{}},
+		{"let x = 1;", []string{"1:5: x is never used (unused)"}},
+		{"let _x = 1; export let y = 2;", []string{}},
+		{"let f = fn(x) { let y = x; 1 }; f(1)", []string{"1:21: y is never used (unused)"}},
+		{"let f = fn(unused) { 1 }; f(1)", []string{}},
+		{"y", []string{"1:1: undefined identifier: y (undefined)"}},
+		{"let x = x + 1; x", []string{"1:9: undefined identifier: x (undefined)"}},
+		{"f(); let f = fn() { 1 }; f()", []string{"1:1: undefined identifier: f (undefined)"}},
+		{"let f = fn() { g() }; let g = fn() { 1 }; f()", []string{}},
+		{"let fib = fn(n) { if (n < 2) { n } else { fib(n - 1) } }; fib(5)", []string{}},
+		{"len([1])", []string{}},
+		{"let x = 1; let f = fn(x) { x }; f(x)", []string{"1:23: x shadows the binding on line 1 (shadow)"}},
+		{"let f = fn() { let f = 1; f }; f()", []string{"1:20: f shadows the binding on line 1 (shadow)"}},
+		{"let len = fn(x) { 0 }; len(1)", []string{"1:5: len shadows a builtin (shadow)"}},
+		{"let x = 1; let x = x + 1; x", []string{}},
+		{"let f = fn() { return 1; 2 }; f()", []string{"1:26: unreachable code (unreachable)"}},
+		{"let f = fn() { return 1; let x = 2; 3; }; f()", []string{
+			"1:26: unreachable code (unreachable)",
+			"1:30: x is never used (unused)",
+		}},
+		{"let f = fn(a) { if (a) { return 1; } else { return 2; }; a }; f(1)",
+			[]string{"1:58: unreachable code (unreachable)"}},
+		{"let f = fn(a) { if (a) { return 1; }; a }; f(1)", []string{}},
+		{"let unless = macro(c, a) { quote(if (!(unquote(c))) { unquote(a) }) }; unless(true, 1)",
+			[]string{}},
+		{"let m = macro(a) { quote(unquote(b)) }; m(1)",
+			[]string{"1:34: undefined identifier: b (undefined)"}},
+	} {
+		diagnostics := New(WithGlobals("len")).Program(parse(t, tt.input))
+
+		got := []string{}
+		for _, d := range diagnostics {
+			got = append(got, d.String())
+		}
+		if len(got) != len(tt.expected) {
+			t.Errorf("%s: wrong diagnostics.\nwant=%q\ngot=%q", tt.input, tt.expected, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.expected[i] {
+				t.Errorf("%s: wrong diagnostics.\nwant=%q\ngot=%q", tt.input, tt.expected, got)
+				break
+			}
+		}
+	}
+}
+
+func TestWithChecks(t *testing.T) {
+	input := "let x = y; let f = fn() { return 1; 2 }; f()"
+
+	for _, tt := range []struct {
+		checks   Check
+		expected int
+	}{
+		{AllChecks, 3},
+		{NoChecks, 0},
+		{Unused, 1},
+		{Undefined | Unreachable, 2},
+		{Shadow, 0},
+	} {
+		diagnostics := New(WithChecks(tt.checks)).Program(parse(t, input))
+		if len(diagnostics) != tt.expected {
+			t.Errorf("checks %s: expected %d diagnostics, got %v", tt.checks, tt.expected, diagnostics)
+		}
+		for _, d := range diagnostics {
+			if tt.checks&d.Check == 0 {
+				t.Errorf("checks %s: unexpected diagnostic %s", tt.checks, d)
+			}
+		}
+	}
+}
+
+func TestParseChecks(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected Check
+		err      string
+	}{
+		{"unused", Unused, ""},
+		{"shadow, undefined", Shadow | Undefined, ""},
+		{"all", AllChecks, ""},
+		{"unused,bogus", NoChecks, `unknown check "bogus"`},
+	} {
+		checks, err := ParseChecks(tt.input)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("ParseChecks(%q): expected error %q, got %v", tt.input, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseChecks(%q) failed: %s", tt.input, err)
+		}
+		if checks != tt.expected {
+			t.Errorf("ParseChecks(%q) = %s, want %s", tt.input, checks, tt.expected)
+		}
+	}
+}
+
+func TestDiagnosticJSON(t *testing.T) {
+	d := Diagnostic{Line: 2, Column: 5, Check: Unused, Message: "x is never used"}
+
+	b, err := json.Marshal(d)
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %s", err)
+	}
+	expected := `{"line":2,"column":5,"check":"unused","message":"x is never used"}`
+	if string(b) != expected {
+		t.Errorf("wrong JSON. want=%s, got=%s", expected, b)
+	}
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("could not parse %q: %s", input, p.Errors())
+	}
+	return program
+}
@@ -1,10 +1,73 @@
 package main
 
 import (
-	"monkey/repl"
+	"fmt"
+	"io"
 	"os"
+
+	"monkey/repl"
 )
 
+const usage = `Usage: monkey [command] [arguments]
+
+Commands:
+	run [-check] <file>
+	                 evaluate a script, type checking it first with -check
+	repl             start an interactive session
+	eval [-check] -e <expr>
+	                 evaluate an expression and print its result
+	tokens <file>    print the tokens of a script
+	ast <file>       print the syntax tree of a script
+	fmt [-l] [-w] [files]
+	                 format scripts, or the standard input without files
+	lint [-checks list] [-json] [files]
+	                 report likely mistakes in scripts, or the standard input
+	check [files]    report type errors in scripts, or the standard input
+
+Running 'monkey <file>' is the same as 'monkey run <file>', which allows
+scripts to start with a '#!/usr/bin/env monkey' line. Without arguments,
+the REPL is started.
+`
+
 func main() {
-	repl.Start(os.Stdin, os.Stdout)
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command described by args and returns the status the
+// process should exit with.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		repl.Start(stdin, stdout, repl.WithErrorWriter(stderr))
+		return 0
+	}
+	cmd, args := args[0], args[1:]
+
+	switch cmd {
+	case "run":
+		return runCmd(args, stdout, stderr)
+	case "repl":
+		repl.Start(stdin, stdout, repl.WithErrorWriter(stderr))
+		return 0
+	case "eval":
+		return evalCmd(args, stdout, stderr)
+	case "tokens":
+		return tokensCmd(args, stdout, stderr)
+	case "ast":
+		return astCmd(args, stdout, stderr)
+	case "fmt":
+		return fmtCmd(args, stdin, stdout, stderr)
+	case "lint":
+		return lintCmd(args, stdin, stdout, stderr)
+	case "check":
+		return checkCmd(args, stdin, stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		io.WriteString(stdout, usage)
+		return 0
+	default:
+		if _, err := os.Stat(cmd); err == nil {
+			return runCmd(append([]string{cmd}, args...), stdout, stderr)
+		}
+		fmt.Fprintf(stderr, "monkey: unknown command %q\n\n%s", cmd, usage)
+		return 2
+	}
 }
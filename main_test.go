@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	files := map[string]string{
+		"hello.mk":   "println(\"hello\")\n",
+		"shebang.mk": "#!/usr/bin/env monkey\nprintln(1 + 2)\n",
+		"syntax.mk":  "#!/usr/bin/env monkey\nlet x = 1 +;\n",
+		"failing.mk": "let x = 1;\nx + true\n",
+		"typed.mk":   "let f = fn(x) { x - 1 };\nif (false) { f(\"a\") };\nprintln(\"ran\")\n",
+		"lib.mk":     "export let double = fn(x) { x * 2 };\n",
+		"main.mk":    "let lib = import \"lib\";\nprintln(lib[\"double\"](21))\n",
+		"messy.mk":   "let x=1\n",
+		"tidy.mk":    "let x = 1;\n",
+	}
+
+	for _, tt := range []struct {
+		args   []string
+		stdin  string
+		code   int
+		stdout string
+		stderr string
+		// after holds the expected contents of files once the command ran.
+		after map[string]string
+	}{
+		{args: []string{"help"}, stdout: usage},
+		{args: []string{"bogus"}, code: 2, stderr: "monkey: unknown command \"bogus\"\n\n" + usage},
+
+		{args: []string{"run", "$DIR/hello.mk"}, stdout: "hello\n"},
+		{args: []string{"$DIR/hello.mk"}, stdout: "hello\n"},
+		{args: []string{"run", "$DIR/shebang.mk"}, stdout: "3\n"},
+		{args: []string{"run", "$DIR/main.mk"}, stdout: "42\n"},
+		{args: []string{"run", "$DIR/typed.mk"}, stdout: "ran\n"},
+		{args: []string{"run", "-check", "$DIR/typed.mk"}, code: 1,
+			stderr: "$DIR/typed.mk:2:16: type mismatch: expected int, got string\n"},
+		{args: []string{"run", "-check", "$DIR/hello.mk"}, stdout: "hello\n"},
+		{args: []string{"run", "$DIR/syntax.mk"}, code: 1,
+			stderr: "$DIR/syntax.mk:2:12: No prefix parse function for ';' found.\n"},
+		{args: []string{"run", "$DIR/failing.mk"}, code: 1,
+			stderr: "$DIR/failing.mk: error: type mismatch: INTEGER + BOOLEAN\n"},
+		{args: []string{"run", "$DIR/missing.mk"}, code: 1,
+			stderr: "monkey: open $DIR/missing.mk: no such file or directory\n"},
+		{args: []string{"run"}, code: 2, stderr: "usage: monkey run [-check] <file>\n"},
+
+		{args: []string{"eval", "-e", "1 + 2"}, stdout: "3\n"},
+		{args: []string{"eval", "-e", `println("hi")`}, stdout: "hi\nnull\n"},
+		{args: []string{"eval", "-e", "1 +"}, code: 1,
+			stderr: "-e:1:4: No prefix parse function for 'EOF' found.\n"},
+		{args: []string{"eval", "-e", "-true"}, code: 1,
+			stderr: "-e: error: unknown operation: -BOOLEAN\n"},
+		{args: []string{"eval", "-check", "-e", `"a" - 1`}, code: 1,
+			stderr: "-e:1:1: type mismatch: string - int\n"},
+		{args: []string{"eval", "-check", "-e", "1 + 2"}, stdout: "3\n"},
+		{args: []string{"eval"}, code: 2, stderr: "usage: monkey eval [-check] -e <expr>\n"},
+
+		{args: []string{"fmt"}, stdin: "let x=1", stdout: "let x = 1;\n"},
+		{args: []string{"fmt"}, stdin: "#!/usr/bin/env monkey\nlet x = ;", code: 1,
+			stderr: "<stdin>:2:9: No prefix parse function for ';' found.\n"},
+		{args: []string{"fmt", "-w"}, code: 2, stderr: "monkey: cannot use -w with the standard input\n"},
+		{args: []string{"fmt", "-l", "$DIR/messy.mk", "$DIR/tidy.mk"}, stdout: "$DIR/messy.mk\n"},
+		{args: []string{"fmt", "-w", "$DIR/messy.mk"},
+			after: map[string]string{"messy.mk": "let x = 1;\n"}},
+
+		{args: []string{"check", "$DIR/typed.mk"}, code: 1,
+			stdout: "$DIR/typed.mk:2:16: type mismatch: expected int, got string\n"},
+		{args: []string{"check", "$DIR/hello.mk"}},
+	} {
+		dir := t.TempDir()
+		for name, src := range files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		expand := func(s string) string { return strings.ReplaceAll(s, "$DIR", dir) }
+		args := make([]string, len(tt.args))
+		for i, arg := range tt.args {
+			args[i] = expand(arg)
+		}
+
+		var stdout, stderr strings.Builder
+		code := run(args, strings.NewReader(tt.stdin), &stdout, &stderr)
+
+		name := strings.Join(tt.args, " ")
+		if code != tt.code {
+			t.Errorf("%s: wrong exit code. want=%d, got=%d", name, tt.code, code)
+		}
+		if got, want := stdout.String(), expand(tt.stdout); got != want {
+			t.Errorf("%s: wrong output. want=%q, got=%q", name, want, got)
+		}
+		if got, want := stderr.String(), expand(tt.stderr); got != want {
+			t.Errorf("%s: wrong errors. want=%q, got=%q", name, want, got)
+		}
+		for file, want := range tt.after {
+			got, err := os.ReadFile(filepath.Join(dir, file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != want {
+				t.Errorf("%s: wrong contents of %s. want=%q, got=%q", name, file, want, got)
+			}
+		}
+	}
+}
@@ -1,8 +1,20 @@
 package object
 
+import (
+	"sort"
+
+	"monkey/ast"
+)
+
 type Environment struct {
 	store map[string]Object
 	outer *Environment
+
+	// A frame keeps the variables of a resolved function in slots, named
+	// by names, rather than in store.
+	frame bool
+	names []string
+	slots []Object
 }
 
 func NewEnv() *Environment {
@@ -17,15 +29,97 @@ func NewEnclosedEnvironment(outer *Environment) *Environment {
 	return env
 }
 
+// NewFrame returns an environment for a call to a resolved function, with
+// an empty slot for each of the given names.
+func NewFrame(outer *Environment, names []string) *Environment {
+	return &Environment{
+		outer: outer,
+		frame: true,
+		names: names,
+		slots: make([]Object, len(names)),
+	}
+}
+
 func (e *Environment) Get(name string) (Object, bool) {
-	obj, ok := e.store[name]
-	if !ok && e.outer != nil {
-		obj, ok = e.outer.Get(name)
+	for ; e != nil; e = e.outer {
+		if obj, ok := e.store[name]; ok {
+			return obj, true
+		}
+		for i, n := range e.names {
+			if n == name && e.slots[i] != nil {
+				return e.slots[i], true
+			}
+		}
 	}
-	return obj, ok
+	return nil, false
 }
 
 func (e *Environment) Insert(name string, val Object) Object {
+	for i, n := range e.names {
+		if n == name {
+			e.slots[i] = val
+			return val
+		}
+	}
+	if e.store == nil {
+		e.store = make(map[string]Object)
+	}
 	e.store[name] = val
 	return val
 }
+
+// Lookup returns the value of the variable ident refers to, going straight
+// to its slot if it is a local one.
+func (e *Environment) Lookup(ident *ast.Identifier) (Object, bool) {
+	switch ident.Scope {
+	case ast.LocalScope:
+		env := e
+		for i := 0; i < ident.Depth && env != nil; i++ {
+			env = env.outer
+		}
+		if env == nil || !env.frame || ident.Index >= len(env.slots) {
+			return e.Get(ident.Value)
+		}
+		if obj := env.slots[ident.Index]; obj != nil {
+			return obj, true
+		}
+		// The variable is not bound yet, such as when it is defined by an
+		// if branch that did not run, so look for the name further out.
+		return env.outer.Get(ident.Value)
+	case ast.GlobalScope:
+		env := e
+		for env.frame && env.outer != nil {
+			env = env.outer
+		}
+		return env.Get(ident.Value)
+	default:
+		return e.Get(ident.Value)
+	}
+}
+
+// Define binds the variable ident refers to, in its slot if it is a local
+// one.
+func (e *Environment) Define(ident *ast.Identifier, val Object) Object {
+	if ident.Scope == ast.LocalScope && e.frame && ident.Index < len(e.slots) {
+		e.slots[ident.Index] = val
+		return val
+	}
+	return e.Insert(ident.Value, val)
+}
+
+// Names returns the names bound in this environment, without those of the
+// enclosing ones, in sorted order.
+func (e *Environment) Names() []string {
+	names := make([]string, 0, len(e.store)+len(e.names))
+	for name := range e.store {
+		names = append(names, name)
+	}
+	for i, name := range e.names {
+		if e.slots[i] != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
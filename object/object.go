@@ -22,6 +22,9 @@ const (
 	BUILTIN_OBJ  = "BUILTIN"
 	ARRAY_OBJ    = "ARRAY"
 	HASH_OBJ     = "HASH"
+	MODULE_OBJ   = "MODULE"
+	QUOTE_OBJ    = "QUOTE"
+	MACRO_OBJ    = "MACRO"
 )
 
 type Object interface {
@@ -59,6 +62,9 @@ type Function struct {
 	Parameters []*ast.Identifier
 	Body       *ast.BlockStatement
 	Env        *Environment
+	// Locals names the slots of the frames the function runs in, or is nil
+	// if its body was not resolved.
+	Locals []string
 }
 
 func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
@@ -80,6 +86,39 @@ func (f *Function) Inspect() string {
 	return out.String()
 }
 
+// Quote holds code that was not evaluated, as produced by `quote`.
+type Quote struct {
+	Node ast.Node
+}
+
+func (q *Quote) Type() ObjectType { return QUOTE_OBJ }
+func (q *Quote) Inspect() string  { return "QUOTE(" + q.Node.String() + ")" }
+
+type Macro struct {
+	Parameters []*ast.Identifier
+	Body       *ast.BlockStatement
+	Env        *Environment
+}
+
+func (m *Macro) Type() ObjectType { return MACRO_OBJ }
+func (m *Macro) Inspect() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, param := range m.Parameters {
+		params = append(params, param.String())
+	}
+
+	out.WriteString("macro")
+	out.WriteString("(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") {\n")
+	out.WriteString(m.Body.String())
+	out.WriteString("\n}")
+
+	return out.String()
+}
+
 type Error struct {
 	Message string
 }
@@ -158,6 +197,60 @@ type HashPair struct {
 
 type Hash struct {
 	Pairs map[HashKey]HashPair
+	// Keys holds the keys of Pairs in the order they were inserted, which
+	// is the order in which the hash is iterated.
+	Keys []HashKey
+}
+
+func NewHash() *Hash {
+	return &Hash{Pairs: make(map[HashKey]HashPair)}
+}
+
+// Set inserts pair under key. A key that is already present keeps its
+// position, while new ones are placed after every other key.
+func (h *Hash) Set(key HashKey, pair HashPair) {
+	if _, ok := h.Pairs[key]; !ok {
+		h.Keys = append(h.Keys, key)
+	}
+	h.Pairs[key] = pair
+}
+
+func (h *Hash) Delete(key HashKey) {
+	if _, ok := h.Pairs[key]; !ok {
+		return
+	}
+	delete(h.Pairs, key)
+
+	for i, k := range h.Keys {
+		if k == key {
+			h.Keys = append(h.Keys[:i:i], h.Keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// OrderedPairs returns the pairs of the hash in insertion order.
+func (h *Hash) OrderedPairs() []HashPair {
+	pairs := make([]HashPair, 0, len(h.Keys))
+	for _, key := range h.Keys {
+		pairs = append(pairs, h.Pairs[key])
+	}
+	return pairs
+}
+
+// Copy returns a hash with the same pairs, which can be modified without
+// affecting the original one.
+func (h *Hash) Copy() *Hash {
+	c := &Hash{
+		Pairs: make(map[HashKey]HashPair, len(h.Pairs)),
+		Keys:  make([]HashKey, len(h.Keys)),
+	}
+	for k, v := range h.Pairs {
+		c.Pairs[k] = v
+	}
+	copy(c.Keys, h.Keys)
+
+	return c
 }
 
 func (h *Hash) Type() ObjectType { return HASH_OBJ }
@@ -165,7 +258,7 @@ func (h *Hash) Inspect() string {
 	var out bytes.Buffer
 
 	pairs := []string{}
-	for _, pair := range h.Pairs {
+	for _, pair := range h.OrderedPairs() {
 		pairs = append(pairs, fmt.Sprintf("%s: %s",
 			pair.Key.Inspect(), pair.Value.Inspect()))
 	}
@@ -179,3 +272,21 @@ func (h *Hash) Inspect() string {
 type Hashable interface {
 	HashKey() HashKey
 }
+
+// Module holds the bindings exported by an imported source file, which are
+// looked up by name like the pairs of a hash.
+type Module struct {
+	Path    string
+	Exports *Hash
+}
+
+func (m *Module) Type() ObjectType { return MODULE_OBJ }
+func (m *Module) Inspect() string  { return fmt.Sprintf("module %q", m.Path) }
+
+// Get returns the value exported under name.
+func (m *Module) Get(name string) (Object, bool) {
+	key := &String{Value: name}
+	pair, ok := m.Exports.Pairs[key.HashKey()]
+
+	return pair.Value, ok
+}
@@ -1,6 +1,10 @@
 package object
 
-import "testing"
+import (
+	"testing"
+
+	"monkey/ast"
+)
 
 func TestStringHashKey(t *testing.T) {
 	a1 := &String{Value: "a"}
@@ -20,3 +24,61 @@ func TestStringHashKey(t *testing.T) {
 		t.Errorf("Strings with different content have same has keys")
 	}
 }
+
+func TestHashOrder(t *testing.T) {
+	h := NewHash()
+
+	for _, key := range []string{"c", "a", "b", "a"} {
+		k := &String{Value: key}
+		h.Set(k.HashKey(), HashPair{Key: k, Value: &Integer{Value: 1}})
+	}
+	if h.Inspect() != "{c: 1, a: 1, b: 1}" {
+		t.Errorf("Wrong order after inserting. Got %s", h.Inspect())
+	}
+
+	c := h.Copy()
+	c.Delete((&String{Value: "a"}).HashKey())
+
+	if c.Inspect() != "{c: 1, b: 1}" {
+		t.Errorf("Wrong order after deleting. Got %s", c.Inspect())
+	}
+	if h.Inspect() != "{c: 1, a: 1, b: 1}" {
+		t.Errorf("Copy shares state with the original. Got %s", h.Inspect())
+	}
+}
+
+func TestFrame(t *testing.T) {
+	global := NewEnv()
+	global.Insert("g", &Integer{Value: 1})
+	frame := NewFrame(global, []string{"x", "y"})
+
+	x := &ast.Identifier{Value: "x", Scope: ast.LocalScope, Index: 0}
+	y := &ast.Identifier{Value: "y", Scope: ast.LocalScope, Index: 1}
+	g := &ast.Identifier{Value: "g", Scope: ast.GlobalScope}
+
+	frame.Define(x, &Integer{Value: 2})
+	if obj, ok := frame.Lookup(x); !ok || obj.(*Integer).Value != 2 {
+		t.Errorf("x not found in its slot. got=%v", obj)
+	}
+	if obj, ok := frame.Get("x"); !ok || obj.(*Integer).Value != 2 {
+		t.Errorf("x not found by name. got=%v", obj)
+	}
+	if _, ok := frame.Lookup(y); ok {
+		t.Errorf("y found before being defined")
+	}
+	if obj, ok := frame.Lookup(g); !ok || obj.(*Integer).Value != 1 {
+		t.Errorf("g not found among globals. got=%v", obj)
+	}
+
+	inner := NewFrame(frame, []string{"z"})
+	outerX := &ast.Identifier{Value: "x", Scope: ast.LocalScope, Depth: 1, Index: 0}
+	if obj, ok := inner.Lookup(outerX); !ok || obj.(*Integer).Value != 2 {
+		t.Errorf("x not found from the inner frame. got=%v", obj)
+	}
+
+	inner.Insert("w", &Integer{Value: 3})
+	names := inner.Names()
+	if len(names) != 1 || names[0] != "w" {
+		t.Errorf("wrong names. got=%q", names)
+	}
+}
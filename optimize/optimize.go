@@ -0,0 +1,274 @@
+// Package optimize rewrites programs ahead of evaluation, so that work not
+// depending on anything at runtime is done once rather than every time the
+// code runs.
+//
+// Operations on literals are folded into a literal, unless evaluating them
+// fails, in which case they are left for the error to show up at runtime.
+// If expressions whose condition is a literal are replaced with the branch
+// that would run, and statements following a return are dropped.
+//
+// Quoted code, including the arguments of macros defined by the program, is
+// left as written.
+package optimize
+
+import (
+	"strconv"
+
+	"monkey/ast"
+	"monkey/token"
+)
+
+// Program optimizes program in place.
+func Program(program *ast.Program) {
+	o := &optimizer{quoted: quoted(program)}
+	ast.Modify(program, o.modify)
+}
+
+type optimizer struct {
+	// quoted holds the nodes of quoted code, which must not be changed.
+	quoted map[ast.Node]bool
+}
+
+func (o *optimizer) modify(node ast.Node) ast.Node {
+	if o.quoted[node] {
+		return node
+	}
+
+	switch node := node.(type) {
+	case *ast.PrefixExpression:
+		return foldPrefix(node)
+	case *ast.InfixExpression:
+		return foldInfix(node)
+	case *ast.IfExpression:
+		return pruneIf(node)
+	case *ast.BlockStatement:
+		node.Statements = statements(node.Statements)
+	case *ast.Program:
+		node.Statements = statements(node.Statements)
+	}
+	return node
+}
+
+// quoted returns the nodes within the arguments of calls to `quote`, and of
+// calls to the macros defined at the top level of program.
+func quoted(program *ast.Program) map[ast.Node]bool {
+	macros := map[string]bool{"quote": true}
+	for _, stmt := range program.Statements {
+		if let, ok := stmt.(*ast.LetStatement); ok {
+			if _, ok := let.Value.(*ast.MacroLiteral); ok {
+				macros[let.Name.Value] = true
+			}
+		}
+	}
+
+	nodes := make(map[ast.Node]bool)
+	ast.Inspect(program, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpression)
+		if !ok {
+			return true
+		}
+		if ident, ok := call.Function.(*ast.Identifier); !ok || !macros[ident.Value] {
+			return true
+		}
+		for _, arg := range call.Arguments {
+			ast.Inspect(arg, func(node ast.Node) bool {
+				if node != nil {
+					nodes[node] = true
+				}
+				return true
+			})
+		}
+		return false
+	})
+	return nodes
+}
+
+func foldPrefix(node *ast.PrefixExpression) ast.Expression {
+	switch node.Operator {
+	case "-":
+		if right, ok := node.Right.(*ast.IntegerLiteral); ok {
+			return integer(node.Token, -right.Value)
+		}
+	case "!":
+		// Only false and null are falsy, and there is no null literal.
+		switch right := node.Right.(type) {
+		case *ast.Boolean:
+			return boolean(node.Token, !right.Value)
+		case *ast.IntegerLiteral, *ast.StringLiteral:
+			return boolean(node.Token, false)
+		}
+	}
+	return node
+}
+
+func foldInfix(node *ast.InfixExpression) ast.Expression {
+	tok := ast.StartToken(node.Left)
+
+	switch left := node.Left.(type) {
+	case *ast.IntegerLiteral:
+		right, ok := node.Right.(*ast.IntegerLiteral)
+		if !ok {
+			return node
+		}
+		a, b := left.Value, right.Value
+		switch node.Operator {
+		case "+":
+			return integer(tok, a+b)
+		case "-":
+			return integer(tok, a-b)
+		case "*":
+			return integer(tok, a*b)
+		case "/":
+			if b != 0 {
+				return integer(tok, a/b)
+			}
+		case "<":
+			return boolean(tok, a < b)
+		case ">":
+			return boolean(tok, a > b)
+		case "==":
+			return boolean(tok, a == b)
+		case "!=":
+			return boolean(tok, a != b)
+		}
+	case *ast.Boolean:
+		right, ok := node.Right.(*ast.Boolean)
+		if !ok {
+			return node
+		}
+		switch node.Operator {
+		case "==":
+			return boolean(tok, left.Value == right.Value)
+		case "!=":
+			return boolean(tok, left.Value != right.Value)
+		}
+	case *ast.StringLiteral:
+		// Strings are compared by identity, so only concatenation is known
+		// ahead of time.
+		right, ok := node.Right.(*ast.StringLiteral)
+		if ok && node.Operator == "+" {
+			return str(tok, left.Value+right.Value)
+		}
+	}
+	return node
+}
+
+// pruneIf drops the branch of ie that can never run. If the other one is a
+// single expression, it takes the place of ie.
+func pruneIf(ie *ast.IfExpression) ast.Expression {
+	cond, ok := truth(ie.Condition)
+	if !ok {
+		return ie
+	}
+
+	if cond {
+		ie.Alternative = nil
+		if e := singleExpression(ie.Consequence); e != nil {
+			return e
+		}
+	} else {
+		ie.Consequence = &ast.BlockStatement{
+			Token:  ie.Consequence.Token,
+			Rbrace: ie.Consequence.Rbrace,
+		}
+		if ie.Alternative != nil {
+			if e := singleExpression(ie.Alternative); e != nil {
+				return e
+			}
+		}
+	}
+	return ie
+}
+
+// statements splices the branches of if statements with a known condition
+// into stmts, and drops the statements following a return.
+func statements(stmts []ast.Statement) []ast.Statement {
+	result := make([]ast.Statement, 0, len(stmts))
+
+	for i, stmt := range stmts {
+		added := []ast.Statement{stmt}
+		// An if with nothing to run has a value of its own, which is kept
+		// when it is the last statement.
+		if branch, ok := knownBranch(stmt); ok && (len(branch) > 0 || i < len(stmts)-1) {
+			added = branch
+		}
+
+		for _, s := range added {
+			result = append(result, s)
+			if _, ok := s.(*ast.ReturnStatement); ok {
+				return result
+			}
+		}
+	}
+	return result
+}
+
+// knownBranch returns the statements that stmt runs, if it is an if whose
+// condition is a literal.
+func knownBranch(stmt ast.Statement) ([]ast.Statement, bool) {
+	es, ok := stmt.(*ast.ExpressionStatement)
+	if !ok {
+		return nil, false
+	}
+	ie, ok := es.Expression.(*ast.IfExpression)
+	if !ok {
+		return nil, false
+	}
+	cond, ok := truth(ie.Condition)
+	if !ok {
+		return nil, false
+	}
+
+	if cond {
+		return ie.Consequence.Statements, true
+	}
+	if ie.Alternative != nil {
+		return ie.Alternative.Statements, true
+	}
+	return nil, true
+}
+
+// truth returns whether e is truthy, if e is a literal.
+func truth(e ast.Expression) (bool, bool) {
+	switch e := e.(type) {
+	case *ast.Boolean:
+		return e.Value, true
+	case *ast.IntegerLiteral, *ast.StringLiteral:
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+func singleExpression(b *ast.BlockStatement) ast.Expression {
+	if len(b.Statements) != 1 {
+		return nil
+	}
+	if es, ok := b.Statements[0].(*ast.ExpressionStatement); ok {
+		return es.Expression
+	}
+	return nil
+}
+
+func integer(at token.Token, value int64) *ast.IntegerLiteral {
+	tok := token.Token{
+		Type:    token.INT,
+		Literal: strconv.FormatInt(value, 10),
+		Line:    at.Line,
+		Column:  at.Column,
+	}
+	return &ast.IntegerLiteral{Token: tok, Value: value}
+}
+
+func boolean(at token.Token, value bool) *ast.Boolean {
+	tok := token.Token{Type: token.FALSE, Literal: "false", Line: at.Line, Column: at.Column}
+	if value {
+		tok.Type, tok.Literal = token.TRUE, "true"
+	}
+	return &ast.Boolean{Token: tok, Value: value}
+}
+
+func str(at token.Token, value string) *ast.StringLiteral {
+	tok := token.Token{Type: token.STRING, Literal: value, Line: at.Line, Column: at.Column}
+	return &ast.StringLiteral{Token: tok, Value: value}
+}
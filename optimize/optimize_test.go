@@ -0,0 +1,109 @@
+package optimize
+
+import (
+	"testing"
+
+	"monkey/ast"
+	"monkey/eval"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+)
+
+func TestProgram(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected string
+	}{
+		{"60 * 60 * 24", "86400"},
+		{"let x = 1 + 2 * 3 - 4 / 2;", "let x = 5;"},
+		{"-(2 + 3)", "-5"},
+		{"!true; !0; !\"\"", "falsefalsefalse"},
+		{"1 < 2; 3 > 4; 5 == 5; 6 != 6", "truefalsetruefalse"},
+		{"true == false; true != false", "falsetrue"},
+		{`"foo" + "bar" + "baz"`, `foobarbaz`},
+		{"x + 1 * 2", "(x + 2)"},
+		{"1 + 2 + x", "(3 + x)"},
+		{"x + 1 + 2", "((x + 1) + 2)"},
+		{"let f = fn(x) { x * (60 * 60) };", "let f = fn(x) (x * 3600);"},
+		{"if (true) { a } else { b }", "a"},
+		{"if (false) { a } else { b }", "b"},
+		{"if (1 < 2) { a } else { b }", "a"},
+		{"if (0) { a }", "a"},
+		{"let v = if (false) { a };", "let v = iffalse ;"},
+		{"if (true) { let a = 1; a } else { b }; c", "let a = 1;ac"},
+		{"if (false) { a; b }; c", "c"},
+		{"let f = fn() { return 1; 2; 3 };", "let f = fn() return 1;;"},
+		{"let f = fn() { if (true) { return 1; }; 2 };", "let f = fn() return 1;;"},
+		{"let f = fn(x) { if (x) { return 1; 2 } };", "let f = fn(x) ifx return 1;;"},
+		{"return 1; 2", "return 1;"},
+		// Left for the error to show up when evaluating.
+		{"1 / 0", "(1 / 0)"},
+		{`"a" - "b"`, `(a - b)`},
+		{"1 + true", "(1 + true)"},
+		{"-true", "(-true)"},
+		{`"a" == "a"`, `(a == a)`},
+		// Quoted code is kept as written.
+		{"quote(1 + 2)", "quote((1 + 2))"},
+		{"let m = macro(a) { a }; m(1 + 2); f(1 + 2)",
+			"let m = macro(a) a;m((1 + 2))f(3)"},
+	} {
+		program := parse(t, tt.input)
+		Program(program)
+
+		if program.String() != tt.expected {
+			t.Errorf("%s: wrong result. want=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+// TestSameResult checks that optimized programs evaluate to the same as the
+// original ones.
+func TestSameResult(t *testing.T) {
+	for _, input := range []string{
+		"60 * 60 * 24",
+		"let f = fn(x) { if (true) { let y = x * 2; y } else { 0 } }; f(21)",
+		"let f = fn(x) { if (false) { 1 }; x }; f(3)",
+		"if (false) { 1 }",
+		"if (true) { }",
+		"let x = 1; if (true) { let x = 2; }; x",
+		"let f = fn() { if (1 > 0) { return \"early\"; }; \"late\" }; f()",
+		"let g = fn(n) { if (n < 1) { return 0; }; return n + g(n - 1); 99 }; g(10)",
+		"let d = fn(x) { x / (2 - 2) }; d(1)",
+		`"a" + "b" == "ab"`,
+		`len("con" + "cat")`,
+		"[1 + 1, !5, -(-3)]",
+		`let unless = macro(c, a, b) { quote(if (!(unquote(c))) { unquote(a) } else { unquote(b) }) };
+		 let show = macro(e) { quote(str(unquote(e))) };
+		 [unless(1 > 2, "yes", "no"), show(1 + 2)]`,
+	} {
+		expected := evaluate(parse(t, input))
+
+		program := parse(t, input)
+		Program(program)
+		got := evaluate(program)
+
+		if got != expected {
+			t.Errorf("%s: different result. want=%q, got=%q", input, expected, got)
+		}
+	}
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("could not parse %q: %s", input, p.Errors())
+	}
+	return program
+}
+
+func evaluate(program *ast.Program) string {
+	result := eval.New().Eval(program, object.NewEnv())
+	if result == nil {
+		return "<nil>"
+	}
+	return result.Inspect()
+}
@@ -21,6 +21,9 @@ type Parser struct {
 	curToken  token.Token
 	peekToken token.Token
 
+	// depth is the number of blocks enclosing the current token.
+	depth int
+
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
 }
@@ -69,9 +72,11 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(token.IF, p.parseIfExpression)
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
+	p.registerPrefix(token.MACRO, p.parseMacroLiteral)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
 	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
 	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
+	p.registerPrefix(token.IMPORT, p.parseImportExpression)
 
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
 	p.registerInfix(token.PLUS, p.parseInfixExpression)
@@ -103,6 +108,9 @@ func (p *Parser) ParseProgram() *ast.Program {
 		}
 		p.nextToken()
 	}
+	for _, tok := range p.l.Comments() {
+		program.Comments = append(program.Comments, &ast.Comment{Token: tok})
+	}
 	return program
 }
 
@@ -125,6 +133,8 @@ func (p *Parser) parseStmt() ast.Statement {
 		return p.parseLetStmt()
 	case token.RETURN:
 		return p.parseReturnStmt()
+	case token.EXPORT:
+		return p.parseExportStmt()
 	default:
 		return p.parseExpressionStmt()
 	}
@@ -138,6 +148,14 @@ func (p *Parser) parseLetStmt() *ast.LetStatement {
 	}
 	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		p.nextToken()
+		if stmt.Type = p.parseType(); stmt.Type == nil {
+			return nil
+		}
+	}
+
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
 	}
@@ -165,6 +183,22 @@ func (p *Parser) parseReturnStmt() *ast.ReturnStatement {
 	return stmt
 }
 
+func (p *Parser) parseExportStmt() ast.Statement {
+	stmt := &ast.ExportStatement{Token: p.curToken}
+
+	if p.depth > 0 {
+		p.errorf(p.curToken, "Export is only allowed at the top level.")
+		return nil
+	}
+	if !p.expectPeek(token.LET) {
+		return nil
+	}
+	if stmt.Statement = p.parseLetStmt(); stmt.Statement == nil {
+		return nil
+	}
+	return stmt
+}
+
 func (p *Parser) parseExpressionStmt() *ast.ExpressionStatement {
 	stmt := &ast.ExpressionStatement{Token: p.curToken}
 	stmt.Expression = p.parseExpression(LOWEST)
@@ -230,8 +264,7 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 
 	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
 	if err != nil {
-		msg := fmt.Sprintf("Couldn't parse '%q' as integer.", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.errorf(p.curToken, "Couldn't parse '%q' as integer.", p.curToken.Literal)
 		return nil
 	}
 	lit.Value = value
@@ -286,6 +319,9 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	block := &ast.BlockStatement{Token: p.curToken}
 	block.Statements = []ast.Statement{}
 
+	p.depth++
+	defer func() { p.depth-- }()
+
 	p.nextToken()
 
 	for !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
@@ -295,6 +331,8 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 		}
 		p.nextToken()
 	}
+	block.Rbrace = p.curToken
+
 	return block
 }
 
@@ -304,7 +342,36 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	if !p.expectPeek(token.LPAREN) {
 		return nil
 	}
-	lit.Parameters = p.parseFunctionParameters()
+	lit.Parameters, lit.ParameterTypes = p.parseFunctionParameters()
+
+	if p.peekTokenIs(token.ARROW) {
+		p.nextToken()
+		p.nextToken()
+		if lit.ReturnType = p.parseType(); lit.ReturnType == nil {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	lit.Body = p.parseBlockStatement()
+
+	return lit
+}
+
+func (p *Parser) parseMacroLiteral() ast.Expression {
+	lit := &ast.MacroLiteral{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	var types []ast.TypeExpr
+	lit.Parameters, types = p.parseFunctionParameters()
+	if types != nil {
+		p.errorf(lit.Token, "Macro parameters cannot have types.")
+		return nil
+	}
 
 	if !p.expectPeek(token.LBRACE) {
 		return nil
@@ -314,33 +381,123 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	return lit
 }
 
-func (p *Parser) parseFunctionParameters() []*ast.Identifier {
+// parseFunctionParameters returns the parameters of a function along with
+// their annotated types, the latter being nil if none is annotated.
+func (p *Parser) parseFunctionParameters() ([]*ast.Identifier, []ast.TypeExpr) {
 	identifiers := []*ast.Identifier{}
+	var types []ast.TypeExpr
 
 	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
-		return identifiers
+		return identifiers, nil
 	}
-	p.nextToken()
-
-	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-	identifiers = append(identifiers, ident)
 
-	for p.peekTokenIs(token.COMMA) {
-		p.nextToken()
+	for {
 		p.nextToken()
 		ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 		identifiers = append(identifiers, ident)
+
+		var typ ast.TypeExpr
+		if p.peekTokenIs(token.COLON) {
+			p.nextToken()
+			p.nextToken()
+			if typ = p.parseType(); typ == nil {
+				return nil, nil
+			}
+			if types == nil {
+				types = make([]ast.TypeExpr, len(identifiers)-1)
+			}
+		}
+		if types != nil {
+			types = append(types, typ)
+		}
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
 	}
 	if !p.expectPeek(token.RPAREN) {
+		return nil, nil
+	}
+	return identifiers, types
+}
+
+// parseType parses the type annotation starting at the current token, as
+// in int, [string], {string: int} or fn(int, int) -> bool.
+func (p *Parser) parseType() ast.TypeExpr {
+	switch p.curToken.Type {
+	case token.IDENT:
+		return &ast.NamedType{Token: p.curToken, Name: p.curToken.Literal}
+	case token.LBRACKET:
+		typ := &ast.ArrayType{Token: p.curToken}
+		p.nextToken()
+		if typ.Element = p.parseType(); typ.Element == nil {
+			return nil
+		}
+		if !p.expectPeek(token.RBRACKET) {
+			return nil
+		}
+		return typ
+	case token.LBRACE:
+		typ := &ast.HashType{Token: p.curToken}
+		p.nextToken()
+		if typ.Key = p.parseType(); typ.Key == nil {
+			return nil
+		}
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+		p.nextToken()
+		if typ.Value = p.parseType(); typ.Value == nil {
+			return nil
+		}
+		if !p.expectPeek(token.RBRACE) {
+			return nil
+		}
+		return typ
+	case token.FUNCTION:
+		typ := &ast.FunctionType{Token: p.curToken, Parameters: []ast.TypeExpr{}}
+		if !p.expectPeek(token.LPAREN) {
+			return nil
+		}
+		if p.peekTokenIs(token.RPAREN) {
+			p.nextToken()
+		} else {
+			for {
+				p.nextToken()
+				param := p.parseType()
+				if param == nil {
+					return nil
+				}
+				typ.Parameters = append(typ.Parameters, param)
+				if !p.peekTokenIs(token.COMMA) {
+					break
+				}
+				p.nextToken()
+			}
+			if !p.expectPeek(token.RPAREN) {
+				return nil
+			}
+		}
+		if !p.expectPeek(token.ARROW) {
+			return nil
+		}
+		p.nextToken()
+		if typ.Return = p.parseType(); typ.Return == nil {
+			return nil
+		}
+		return typ
+	default:
+		p.errorf(p.curToken, "Expected a type. Got '%s'.", p.curToken.Type)
 		return nil
 	}
-	return identifiers
 }
 
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	exp := &ast.CallExpression{Token: p.curToken, Function: function}
 	exp.Arguments = p.parseExpressionList(token.RPAREN)
+	exp.Rparen = p.curToken
 
 	return exp
 }
@@ -375,6 +532,7 @@ func (p *Parser) parseArrayLiteral() ast.Expression {
 	array := &ast.ArrayLiteral{Token: p.curToken}
 
 	array.Elements = p.parseExpressionList(token.RBRACKET)
+	array.Rbracket = p.curToken
 
 	return array
 }
@@ -411,6 +569,8 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	if !p.expectPeek(token.RBRACKET) {
 		return nil
 	}
+	exp.Rbracket = p.curToken
+
 	return exp
 }
 
@@ -428,6 +588,7 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 		p.nextToken()
 		v := p.parseExpression(LOWEST)
 		hash.Pairs[k] = v
+		hash.Keys = append(hash.Keys, k)
 
 		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
 			return nil
@@ -437,10 +598,24 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 	if !p.expectPeek(token.RBRACE) {
 		return nil
 	}
+	hash.Rbrace = p.curToken
 
 	return hash
 }
 
+// parseImportExpression only accepts a string literal as path, so that the
+// modules a program depends on are known without evaluating it.
+func (p *Parser) parseImportExpression() ast.Expression {
+	expression := &ast.ImportExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.STRING) {
+		return nil
+	}
+	expression.Path = &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+
+	return expression
+}
+
 func (p *Parser) curTokenIs(t token.TokenType) bool {
 	return p.curToken.Type == t
 }
@@ -473,11 +648,16 @@ func (p *Parser) curPrecedence() int {
 }
 
 func (p *Parser) peekError(t token.TokenType) {
-	msg := fmt.Sprintf("Expected token: '%s'. Got '%s'.", t, p.peekToken.Type)
-	p.errors = append(p.errors, msg)
+	p.errorf(p.peekToken, "Expected token: '%s'. Got '%s'.", t, p.peekToken.Type)
 }
 
 func (p *Parser) noPrefixFnError(t token.TokenType) {
-	msg := fmt.Sprintf("No prefix parse function for '%s' found.", t)
+	p.errorf(p.curToken, "No prefix parse function for '%s' found.", t)
+}
+
+// errorf records an error message prefixed with the position of tok, as in
+// "line:column: message".
+func (p *Parser) errorf(tok token.Token, format string, a ...interface{}) {
+	msg := fmt.Sprintf("%d:%d: ", tok.Line, tok.Column) + fmt.Sprintf(format, a...)
 	p.errors = append(p.errors, msg)
 }
@@ -668,6 +668,33 @@ func TestParsingHashLiterals(t *testing.T) {
 	}
 }
 
+func TestParsingHashLiteralKeyOrder(t *testing.T) {
+	input := `{"c": 1, "a": 2, "b": 3}`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got %T", stmt.Expression)
+	}
+
+	if len(hash.Keys) != 3 {
+		t.Fatalf("wrong key length. got %d", len(hash.Keys))
+	}
+	for i, expected := range []string{"c", "a", "b"} {
+		if hash.Keys[i].String() != expected {
+			t.Errorf("key %d not %q. got %q", i, expected, hash.Keys[i].String())
+		}
+	}
+	if hash.String() != "{c:1, a:2, b:3}" {
+		t.Errorf("hash.String() wrong. got %q", hash.String())
+	}
+}
+
 func TestParsingEmptyHashLiteral(t *testing.T) {
 	input := `{}`
 
@@ -734,6 +761,146 @@ func TestParsingHashLiteralsWithExpressions(t *testing.T) {
 	}
 }
 
+func TestExportStatement(t *testing.T) {
+	l := lexer.New(`export let add = fn(a, b) { a + b };`)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d",
+			len(program.Statements))
+	}
+	stmt, ok := program.Statements[0].(*ast.ExportStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not *ast.ExportStatement. got=%T",
+			program.Statements[0])
+	}
+	if !testLetStatement(t, stmt.Statement, "add") {
+		return
+	}
+	if stmt.String() != "export let add = fn(a, b) (a + b);" {
+		t.Errorf("stmt.String() wrong. got=%q", stmt.String())
+	}
+}
+
+func TestImportExpression(t *testing.T) {
+	l := lexer.New(`let m = import "lib/math";`)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.LetStatement)
+	exp, ok := stmt.Value.(*ast.ImportExpression)
+	if !ok {
+		t.Fatalf("stmt.Value is not *ast.ImportExpression. got=%T", stmt.Value)
+	}
+	if exp.Path.Value != "lib/math" {
+		t.Errorf("exp.Path.Value not %q. got=%q", "lib/math", exp.Path.Value)
+	}
+	if exp.String() != `import "lib/math"` {
+		t.Errorf("exp.String() wrong. got=%q", exp.String())
+	}
+}
+
+func TestMacroLiteralParsing(t *testing.T) {
+	l := lexer.New(`macro(x, y) { x + y; }`)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	macro, ok := stmt.Expression.(*ast.MacroLiteral)
+	if !ok {
+		t.Fatalf("stmt.Expression is not *ast.MacroLiteral. got=%T", stmt.Expression)
+	}
+	if len(macro.Parameters) != 2 {
+		t.Fatalf("macro literal parameters wrong. want 2, got=%d", len(macro.Parameters))
+	}
+	testLiteralExpression(t, macro.Parameters[0], "x")
+	testLiteralExpression(t, macro.Parameters[1], "y")
+
+	if len(macro.Body.Statements) != 1 {
+		t.Fatalf("macro.Body.Statements has not 1 statement. got=%d",
+			len(macro.Body.Statements))
+	}
+	body, ok := macro.Body.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("macro body stmt is not *ast.ExpressionStatement. got=%T",
+			macro.Body.Statements[0])
+	}
+	testInfixExpression(t, body.Expression, "x", "+", "y")
+}
+
+func TestTypeAnnotations(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected string
+	}{
+		{"let x: int = 1;", "let x: int = 1;"},
+		{"let xs: [[string]] = [];", "let xs: [[string]] = [];"},
+		{"let h: {string: [int]} = {};", "let h: {string: [int]} = {};"},
+		{"let f: fn(int, bool) -> fn() -> int = g;", "let f: fn(int, bool) -> fn() -> int = g;"},
+		{"fn(a: string, b: [int]) -> bool { true }", "fn(a: string, b: [int]) -> bool true"},
+		{"fn(a, b: int) { a }", "fn(a, b: int) a"},
+		{"fn() -> int { 1 }", "fn() -> int 1"},
+		{"fn(a) { a }", "fn(a) a"},
+	} {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	program := New(lexer.New("fn(a, b: int, c) {}")).ParseProgram()
+	fn := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if len(fn.ParameterTypes) != 3 || fn.ParameterTypes[0] != nil ||
+		fn.ParameterTypes[1].String() != "int" || fn.ParameterTypes[2] != nil {
+		t.Errorf("wrong parameter types. got=%v", fn.ParameterTypes)
+	}
+
+	program = New(lexer.New("fn(a, b) {}")).ParseProgram()
+	fn = program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if fn.ParameterTypes != nil || fn.ReturnType != nil {
+		t.Errorf("unexpected types. got=%v, %v", fn.ParameterTypes, fn.ReturnType)
+	}
+}
+
+func TestParserErrorPositions(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected string
+	}{
+		{"let = 5;", "1:5: Expected token: 'IDENT'. Got '='."},
+		{"let x = 1;\n  let y 2;", "2:9: Expected token: '='. Got 'INT'."},
+		{"if (true) {\n}\n)", "3:1: No prefix parse function for ')' found."},
+		{"fn() {\n  export let x = 1;\n}", "2:3: Export is only allowed at the top level."},
+		{"export 5;", "1:8: Expected token: 'LET'. Got 'INT'."},
+		{"import x;", "1:8: Expected token: 'STRING'. Got 'IDENT'."},
+		{"let x: = 1;", "1:8: Expected a type. Got '='."},
+		{"fn(a: [int) {}", "1:11: Expected token: ']'. Got ')'."},
+		{"fn() -> fn(int) {}", "1:17: Expected token: '->'. Got '{'."},
+		{"macro(a: int) { a }", "1:1: Macro parameters cannot have types."},
+	} {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("Expected parser errors for %q", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("Wrong error. Expected %q, got %q", tt.expected, errors[0])
+		}
+	}
+}
+
 func checkParserErrors(t *testing.T, p *Parser) {
 	errors := p.Errors()
 	if len(errors) == 0 {
@@ -0,0 +1,129 @@
+package repl
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"monkey/lexer"
+	"monkey/token"
+)
+
+type command struct {
+	usage string
+	help  string
+	run   func(s *session, arg string)
+}
+
+var commands map[string]command
+
+func init() {
+	// Assigned here rather than in the declaration, since `:help` refers
+	// back to the table.
+	commands = map[string]command{
+		"env":    {":env", "list the bindings of the session", (*session).cmdEnv},
+		"ast":    {":ast <expr>", "print the syntax tree of an expression", (*session).cmdAST},
+		"tokens": {":tokens <expr>", "print the tokens of an expression", (*session).cmdTokens},
+		"type":   {":type <expr>", "print the type of an expression's value", (*session).cmdType},
+		"load":   {":load <file>", "evaluate a file in the session", (*session).cmdLoad},
+		"save":   {":save <file>", "write the inputs evaluated so far to a file", (*session).cmdSave},
+		"reset":  {":reset", "discard every binding of the session", (*session).cmdReset},
+		"time":   {":time <expr>", "evaluate an expression and report how long it took", (*session).cmdTime},
+		"help":   {":help", "list the available commands", (*session).cmdHelp},
+	}
+}
+
+var commandOrder = []string{
+	"env", "ast", "tokens", "type", "load", "save", "reset", "time", "help",
+}
+
+// command runs a line starting with ':', as in ":ast 1 + 2".
+func (s *session) command(line string) {
+	name, arg, _ := strings.Cut(strings.TrimPrefix(line, ":"), " ")
+	arg = strings.TrimSpace(arg)
+
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(s.errOut, "unknown command :%s, see :help\n", name)
+		return
+	}
+	if strings.Contains(cmd.usage, "<") && arg == "" {
+		fmt.Fprintf(s.errOut, "usage: %s\n", cmd.usage)
+		return
+	}
+	cmd.run(s, arg)
+}
+
+func (s *session) cmdEnv(string) {
+	for _, name := range s.env.Names() {
+		val, _ := s.env.Get(name)
+		fmt.Fprintf(s.out, "%s = %s\n", name, val.Inspect())
+	}
+}
+
+func (s *session) cmdAST(arg string) {
+	program, ok := s.parse(arg)
+	if !ok {
+		return
+	}
+	for _, stmt := range program.Statements {
+		fmt.Fprintln(s.out, stmt.String())
+	}
+}
+
+func (s *session) cmdTokens(arg string) {
+	l := lexer.New(arg)
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		fmt.Fprintf(s.out, "%s\t%q\n", tok.Type, tok.Literal)
+	}
+}
+
+func (s *session) cmdType(arg string) {
+	program, ok := s.parse(arg)
+	if !ok {
+		return
+	}
+	evaluated := s.interpreter.Eval(program, s.env)
+	if evaluated == nil {
+		fmt.Fprintln(s.out, "no value")
+		return
+	}
+	fmt.Fprintln(s.out, evaluated.Type())
+}
+
+func (s *session) cmdLoad(arg string) {
+	src, err := os.ReadFile(arg)
+	if err != nil {
+		fmt.Fprintln(s.errOut, err)
+		return
+	}
+	s.eval(string(src))
+}
+
+func (s *session) cmdSave(arg string) {
+	transcript := strings.Join(s.transcript, "")
+
+	if err := os.WriteFile(arg, []byte(transcript), 0644); err != nil {
+		fmt.Fprintln(s.errOut, err)
+		return
+	}
+	fmt.Fprintf(s.out, "saved %d inputs to %s\n", len(s.transcript), arg)
+}
+
+func (s *session) cmdReset(string) {
+	s.reset()
+}
+
+func (s *session) cmdTime(arg string) {
+	start := time.Now()
+	s.eval(arg)
+	fmt.Fprintf(s.out, "took %s\n", time.Since(start))
+}
+
+func (s *session) cmdHelp(string) {
+	for _, name := range commandOrder {
+		cmd := commands[name]
+		fmt.Fprintf(s.out, "  %-16s %s\n", cmd.usage, cmd.help)
+	}
+}
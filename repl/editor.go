@@ -0,0 +1,413 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+const (
+	HISTORY_FILE = ".monkey_history"
+	// Number of entries kept in the history, both in memory and on disk.
+	HISTORY_SIZE = 1000
+)
+
+// Keys, as received from a terminal in raw mode.
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyCtrlG     = 7
+	keyCtrlH     = 8
+	keyTab       = 9
+	keyCtrlJ     = 10
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyEnter     = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlR     = 18
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyBackspace = 127
+)
+
+var errInterrupted = errors.New("interrupted")
+
+// editor reads lines from a terminal, allowing them to be edited in place
+// and recalled from a history that persists across sessions.
+type editor struct {
+	in  *bufio.Reader
+	out io.Writer
+	// fd is the terminal put in raw mode while reading, or -1 if the input
+	// does not need it.
+	fd int
+
+	history     []string
+	historyPath string
+
+	// complete returns the candidates for completing the word prefix.
+	complete func(prefix string) []string
+}
+
+func newEditor(in io.Reader, out io.Writer, fd int) *editor {
+	return &editor{in: bufio.NewReader(in), out: out, fd: fd}
+}
+
+// loadHistory reads the history stored in the file at path, and makes every
+// line read from then on be appended to it.
+func (e *editor) loadHistory(path string) {
+	e.historyPath = path
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	lines := strings.Split(strings.TrimRight(string(b), "\n"), "\n")
+	if len(lines) > HISTORY_SIZE {
+		lines = lines[len(lines)-HISTORY_SIZE:]
+		os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600)
+	}
+	for _, line := range lines {
+		if line != "" {
+			e.history = append(e.history, line)
+		}
+	}
+}
+
+func (e *editor) addHistory(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if len(e.history) > 0 && e.history[len(e.history)-1] == line {
+		return
+	}
+	e.history = append(e.history, line)
+	if len(e.history) > HISTORY_SIZE {
+		e.history = e.history[1:]
+	}
+
+	if e.historyPath == "" {
+		return
+	}
+	f, err := os.OpenFile(e.historyPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	fmt.Fprintln(f, line)
+}
+
+// readLine prints prompt and reads a line, returning io.EOF when input ends
+// or Ctrl-D is pressed on an empty line, and errInterrupted on Ctrl-C.
+func (e *editor) readLine(prompt string) (string, error) {
+	if e.fd >= 0 {
+		restore, err := makeRaw(e.fd)
+		if err != nil {
+			return "", err
+		}
+		defer restore()
+	}
+
+	var (
+		buf []rune
+		pos int
+		// Position in the history while browsing it, and the line being
+		// edited before doing so.
+		histPos = len(e.history)
+		pending []rune
+	)
+	e.refresh(prompt, buf, pos)
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			if err == io.EOF && len(buf) > 0 {
+				io.WriteString(e.out, "\r\n")
+				return string(buf), nil
+			}
+			return "", err
+		}
+
+		switch r {
+		case keyEnter, keyCtrlJ:
+			io.WriteString(e.out, "\r\n")
+			line := string(buf)
+			e.addHistory(line)
+			return line, nil
+		case keyCtrlC:
+			io.WriteString(e.out, "^C\r\n")
+			return "", errInterrupted
+		case keyCtrlD:
+			if len(buf) == 0 {
+				io.WriteString(e.out, "\r\n")
+				return "", io.EOF
+			}
+			if pos < len(buf) {
+				buf = append(buf[:pos], buf[pos+1:]...)
+			}
+		case keyCtrlA:
+			pos = 0
+		case keyCtrlE:
+			pos = len(buf)
+		case keyCtrlB:
+			if pos > 0 {
+				pos--
+			}
+		case keyCtrlF:
+			if pos < len(buf) {
+				pos++
+			}
+		case keyBackspace, keyCtrlH:
+			if pos > 0 {
+				buf = append(buf[:pos-1], buf[pos:]...)
+				pos--
+			}
+		case keyCtrlK:
+			buf = buf[:pos]
+		case keyCtrlU:
+			buf = buf[pos:]
+			pos = 0
+		case keyCtrlW:
+			start := pos
+			for start > 0 && buf[start-1] == ' ' {
+				start--
+			}
+			for start > 0 && buf[start-1] != ' ' {
+				start--
+			}
+			buf = append(buf[:start], buf[pos:]...)
+			pos = start
+		case keyCtrlL:
+			io.WriteString(e.out, "\x1b[H\x1b[2J")
+		case keyCtrlP, keyCtrlN:
+			buf, histPos, pending = e.browse(r == keyCtrlP, buf, histPos, pending)
+			pos = len(buf)
+		case keyCtrlR:
+			line, accepted, err := e.search(buf)
+			if err != nil {
+				return "", err
+			}
+			if accepted {
+				io.WriteString(e.out, "\r\n")
+				e.addHistory(line)
+				return line, nil
+			}
+			buf, pos = []rune(line), len([]rune(line))
+		case keyTab:
+			buf, pos = e.completeWord(buf, pos)
+		case keyEscape:
+			switch e.readEscape() {
+			case 'A':
+				buf, histPos, pending = e.browse(true, buf, histPos, pending)
+				pos = len(buf)
+			case 'B':
+				buf, histPos, pending = e.browse(false, buf, histPos, pending)
+				pos = len(buf)
+			case 'C':
+				if pos < len(buf) {
+					pos++
+				}
+			case 'D':
+				if pos > 0 {
+					pos--
+				}
+			case 'H':
+				pos = 0
+			case 'F':
+				pos = len(buf)
+			case '~':
+				if pos < len(buf) {
+					buf = append(buf[:pos], buf[pos+1:]...)
+				}
+			}
+		default:
+			if unicode.IsPrint(r) {
+				buf = append(buf[:pos], append([]rune{r}, buf[pos:]...)...)
+				pos++
+			}
+		}
+		e.refresh(prompt, buf, pos)
+	}
+}
+
+// readEscape reads the rest of an escape sequence, returning 'A' through 'D'
+// for the arrow keys, 'H' and 'F' for home and end, '~' for delete, and 0
+// for anything else.
+func (e *editor) readEscape() rune {
+	r, _, err := e.in.ReadRune()
+	if err != nil || (r != '[' && r != 'O') {
+		return 0
+	}
+	r, _, err = e.in.ReadRune()
+	if err != nil {
+		return 0
+	}
+	if r < '0' || r > '9' {
+		return r
+	}
+
+	// Sequences such as "ESC [ 3 ~", where the digit selects the key.
+	digit := r
+	if r, _, err = e.in.ReadRune(); err != nil || r != '~' {
+		return 0
+	}
+	switch digit {
+	case '1', '7':
+		return 'H'
+	case '4', '8':
+		return 'F'
+	case '3':
+		return '~'
+	}
+	return 0
+}
+
+// browse moves through the history, towards older entries if back is true.
+func (e *editor) browse(
+	back bool,
+	buf []rune,
+	histPos int,
+	pending []rune,
+) ([]rune, int, []rune) {
+
+	if histPos == len(e.history) {
+		pending = buf
+	}
+	if back && histPos > 0 {
+		histPos--
+	} else if !back && histPos < len(e.history) {
+		histPos++
+	}
+
+	if histPos == len(e.history) {
+		return pending, histPos, pending
+	}
+	return []rune(e.history[histPos]), histPos, pending
+}
+
+// search performs an incremental search backwards through the history. It
+// returns the matching line, and whether it was accepted with Enter rather
+// than kept for further editing by pressing any other control key.
+func (e *editor) search(buf []rune) (string, bool, error) {
+	var query []rune
+	match, idx := string(buf), len(e.history)
+
+	find := func(from int) {
+		for i := from; i >= 0 && i < len(e.history); i-- {
+			if strings.Contains(e.history[i], string(query)) {
+				match, idx = e.history[i], i
+				return
+			}
+		}
+	}
+
+	for {
+		fmt.Fprintf(e.out, "\r(reverse-i-search)`%s': %s\x1b[K",
+			string(query), match)
+
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", false, err
+		}
+
+		switch {
+		case r == keyCtrlR:
+			find(idx - 1)
+		case r == keyBackspace || r == keyCtrlH:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				find(len(e.history) - 1)
+			}
+		case r == keyCtrlG || r == keyCtrlC:
+			return string(buf), false, nil
+		case r == keyEnter || r == keyCtrlJ:
+			return match, true, nil
+		case unicode.IsPrint(r):
+			query = append(query, r)
+			if idx == len(e.history) {
+				idx--
+			}
+			find(idx)
+		default:
+			// Leave the key to be handled by the line editor.
+			e.in.UnreadRune()
+			return match, false, nil
+		}
+	}
+}
+
+// completeWord completes the identifier before the cursor. A single
+// candidate is inserted whole, several ones are listed after inserting
+// their common prefix.
+func (e *editor) completeWord(buf []rune, pos int) ([]rune, int) {
+	if e.complete == nil {
+		return buf, pos
+	}
+	start := pos
+	for start > 0 && isIdentRune(buf[start-1]) {
+		start--
+	}
+	prefix := string(buf[start:pos])
+	if prefix == "" {
+		return buf, pos
+	}
+
+	candidates := e.complete(prefix)
+	if len(candidates) == 0 {
+		return buf, pos
+	}
+
+	common := candidates[0]
+	for _, c := range candidates[1:] {
+		for !strings.HasPrefix(c, common) {
+			common = common[:len(common)-1]
+		}
+	}
+	if len(candidates) > 1 && common == prefix {
+		io.WriteString(e.out, "\r\n"+strings.Join(candidates, "  ")+"\r\n")
+		return buf, pos
+	}
+
+	insert := []rune(common[len(prefix):])
+	buf = append(buf[:pos], append(insert, buf[pos:]...)...)
+
+	return buf, pos + len(insert)
+}
+
+func (e *editor) refresh(prompt string, buf []rune, pos int) {
+	var out strings.Builder
+
+	out.WriteString("\r")
+	out.WriteString(prompt)
+	out.WriteString(string(buf))
+	out.WriteString("\x1b[K")
+	if n := len(buf) - pos; n > 0 {
+		fmt.Fprintf(&out, "\x1b[%dD", n)
+	}
+	io.WriteString(e.out, out.String())
+}
+
+func isIdentRune(r rune) bool {
+	return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || r == '_'
+}
+
+// historyPath returns the path of the history file in the user's home, or
+// an empty string if there is no home directory.
+func historyPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, HISTORY_FILE)
+}
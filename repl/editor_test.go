@@ -0,0 +1,90 @@
+package repl
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEditorReadLine(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		history  []string
+		expected string
+	}{
+		{"let x = 1;\r", nil, "let x = 1;"},
+		{"ac\x1b[Db\r", nil, "abc"},
+		{"bc\x01a\x05d\r", nil, "abcd"},
+		{"abcd\x7f\x7f\r", nil, "ab"},
+		{"abc\x1b[D\x1b[D\x1b[3~\r", nil, "ac"},
+		{"let x = 1\x17\x17y\r", nil, "let x y"},
+		{"abc\x02\x02\x0b\r", nil, "a"},
+		{"abc\x02\x15\r", nil, "c"},
+		{"\x1b[A\r", []string{"first", "second"}, "second"},
+		{"\x1b[A\x1b[A\r", []string{"first", "second"}, "first"},
+		{"new\x1b[A\x1b[B\r", []string{"first"}, "new"},
+		{"\x10 + 1\r", []string{"x"}, "x + 1"},
+		{"\x12fir\r", []string{"first", "second", "third"}, "first"},
+		{"\x12i\x12\r", []string{"first", "second", "third"}, "first"},
+		{"\x12sec\x01!\r", []string{"first", "second"}, "!second"},
+		{"fo\t(1)\r", nil, "foobar(1)"},
+		{"ba\t\r", nil, "ba"},
+	} {
+		e := newEditor(strings.NewReader(tt.input), io.Discard, -1)
+		e.history = tt.history
+		e.complete = func(prefix string) []string {
+			var candidates []string
+			for _, c := range []string{"bar", "baz", "foobar"} {
+				if strings.HasPrefix(c, prefix) {
+					candidates = append(candidates, c)
+				}
+			}
+			return candidates
+		}
+
+		line, err := e.readLine(PROMPT)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", tt.input, err)
+			continue
+		}
+		if line != tt.expected {
+			t.Errorf("%q: expected %q, got %q", tt.input, tt.expected, line)
+		}
+	}
+}
+
+func TestEditorControlKeys(t *testing.T) {
+	e := newEditor(strings.NewReader("abc\x03\x04"), io.Discard, -1)
+
+	if _, err := e.readLine(PROMPT); err != errInterrupted {
+		t.Errorf("expected errInterrupted, got %v", err)
+	}
+	if _, err := e.readLine(PROMPT); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestEditorHistoryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), HISTORY_FILE)
+	os.WriteFile(path, []byte("first\n"), 0600)
+
+	e := newEditor(strings.NewReader("second\r\r\x1b[A\x1b[A\r"), io.Discard, -1)
+	e.loadHistory(path)
+
+	for _, expected := range []string{"second", "", "first"} {
+		line, err := e.readLine(PROMPT)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if line != expected {
+			t.Errorf("expected %q, got %q", expected, line)
+		}
+	}
+
+	b, _ := os.ReadFile(path)
+	if string(b) != "first\nsecond\nfirst\n" {
+		t.Errorf("wrong history file contents %q", string(b))
+	}
+}
@@ -0,0 +1,126 @@
+package repl
+
+import (
+	"strconv"
+	"strings"
+
+	"monkey/object"
+)
+
+const (
+	// Arrays and hashes wider than this are printed one element per line.
+	PRETTY_WIDTH = 60
+	INDENT       = "  "
+)
+
+// ANSI escape sequences used when printing in color.
+const (
+	colorReset   = "\x1b[0m"
+	colorRed     = "\x1b[31m"
+	colorGreen   = "\x1b[32m"
+	colorYellow  = "\x1b[33m"
+	colorMagenta = "\x1b[35m"
+	colorCyan    = "\x1b[36m"
+	colorGray    = "\x1b[90m"
+)
+
+// pretty returns a representation of obj meant for reading, where nested
+// arrays and hashes are indented and strings within them are quoted and
+// escaped.
+func pretty(obj object.Object, color bool) string {
+	p := &printer{color: color}
+
+	// A string on its own is shown as is, without quotes.
+	if str, ok := obj.(*object.String); ok {
+		return str.Value
+	}
+	return p.format(obj, 0)
+}
+
+type printer struct {
+	color bool
+}
+
+func (p *printer) format(obj object.Object, depth int) string {
+	switch obj := obj.(type) {
+	case *object.Array:
+		items := make([]string, len(obj.Elements))
+		for i, e := range obj.Elements {
+			items[i] = p.format(e, depth+1)
+		}
+		return p.container("[", "]", items, depth)
+	case *object.Hash:
+		pairs := obj.OrderedPairs()
+
+		items := make([]string, len(pairs))
+		for i, pair := range pairs {
+			items[i] = p.format(pair.Key, depth+1) + ": " +
+				p.format(pair.Value, depth+1)
+		}
+		return p.container("{", "}", items, depth)
+	case *object.String:
+		// Quoting escapes control characters, which would otherwise be
+		// interpreted by the terminal.
+		return p.paint(colorGreen, strconv.Quote(obj.Value))
+	case *object.Integer:
+		return p.paint(colorCyan, obj.Inspect())
+	case *object.Boolean:
+		return p.paint(colorYellow, obj.Inspect())
+	case *object.Null:
+		return p.paint(colorGray, obj.Inspect())
+	case *object.Error:
+		return p.paint(colorRed, obj.Inspect())
+	case *object.Function, *object.Builtin:
+		return p.paint(colorMagenta, obj.Inspect())
+	default:
+		return obj.Inspect()
+	}
+}
+
+// container joins items on a single line if they fit, and otherwise puts
+// each of them on its own line, indented one level deeper than depth.
+func (p *printer) container(open, close string, items []string, depth int) string {
+	flat := open + strings.Join(items, ", ") + close
+	width := PRETTY_WIDTH - len(INDENT)*depth
+
+	if !strings.Contains(flat, "\n") && visibleLen(flat) <= width {
+		return flat
+	}
+
+	var out strings.Builder
+
+	out.WriteString(open + "\n")
+	for i, item := range items {
+		out.WriteString(strings.Repeat(INDENT, depth+1))
+		out.WriteString(item)
+		if i < len(items)-1 {
+			out.WriteString(",")
+		}
+		out.WriteString("\n")
+	}
+	out.WriteString(strings.Repeat(INDENT, depth) + close)
+
+	return out.String()
+}
+
+func (p *printer) paint(color, s string) string {
+	if !p.color {
+		return s
+	}
+	return color + s + colorReset
+}
+
+// visibleLen returns the length of s, not counting ANSI escape sequences.
+func visibleLen(s string) int {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\x1b' {
+			for i < len(s) && s[i] != 'm' {
+				i++
+			}
+			continue
+		}
+		n++
+	}
+	return n
+}
@@ -2,49 +2,249 @@ package repl
 
 import (
 	"bufio"
-	"fmt"
 	"io"
 	"os"
+	"sort"
+	"strings"
 
+	"monkey/ast"
 	"monkey/eval"
 	"monkey/lexer"
 	"monkey/object"
 	"monkey/parser"
+	"monkey/token"
 )
 
-const PROMPT = "> "
+const (
+	PROMPT              = "> "
+	CONTINUATION_PROMPT = ". "
+)
+
+// session is the state kept between inputs of a REPL.
+type session struct {
+	out    io.Writer
+	errOut io.Writer
+	color  bool
+
+	env         *object.Environment
+	interpreter *eval.Interpreter
+	// transcript holds every input that was evaluated, in order.
+	transcript []string
+}
+
+type Option func(*session)
+
+// WithErrorWriter makes errors be written to w, rather than to the output
+// of the REPL.
+func WithErrorWriter(w io.Writer) Option {
+	return func(s *session) {
+		s.errOut = w
+	}
+}
+
+// WithColor enables or disables colored output. By default, it is enabled
+// when the output is a terminal and the NO_COLOR variable is not set.
+func WithColor(enabled bool) Option {
+	return func(s *session) {
+		s.color = enabled
+	}
+}
+
+func newSession(out io.Writer, opts ...Option) *session {
+	s := &session{out: out, errOut: out}
+
+	if f, ok := out.(*os.File); ok && isTerminal(int(f.Fd())) {
+		_, noColor := os.LookupEnv("NO_COLOR")
+		s.color = !noColor
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.reset()
+
+	return s
+}
+
+func (s *session) reset() {
+	s.env = object.NewEnv()
+	s.interpreter = eval.New(
+		eval.WithCapabilities(eval.AllCapabilities),
+		eval.WithOutput(s.out),
+		eval.WithRoot("."),
+		eval.WithModules(os.DirFS(".")),
+	)
+	s.transcript = nil
+}
+
+// lineReader reads the lines typed into the REPL.
+type lineReader interface {
+	readLine(prompt string) (string, error)
+}
+
+// scannerReader reads lines from input that is not a terminal, such as a
+// pipe, so it does not print any prompt.
+type scannerReader struct {
+	scanner *bufio.Scanner
+}
+
+func (r *scannerReader) readLine(prompt string) (string, error) {
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return r.scanner.Text(), nil
+}
+
+// newLineReader returns a line editor when in is a terminal, and reads plain
+// lines otherwise.
+func newLineReader(in io.Reader, out io.Writer, s *session) lineReader {
+	if f, ok := in.(*os.File); ok && isTerminal(int(f.Fd())) {
+		e := newEditor(in, out, int(f.Fd()))
+		e.complete = s.complete
+		if path := historyPath(); path != "" {
+			e.loadHistory(path)
+		}
+		return e
+	}
+	return &scannerReader{scanner: bufio.NewScanner(in)}
+}
+
+// Start runs a REPL reading from in and writing to out. Unless configured
+// otherwise, errors are written to out as well.
+func Start(in io.Reader, out io.Writer, opts ...Option) {
+	s := newSession(out, opts...)
+	r := newLineReader(in, out, s)
 
-func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
-	env := object.NewEnv()
+	var buf strings.Builder
 
 	for {
-		fmt.Printf(PROMPT)
-		scanned := scanner.Scan()
-		if !scanned {
+		prompt := PROMPT
+		if buf.Len() != 0 {
+			prompt = CONTINUATION_PROMPT
+		}
+		line, err := r.readLine(prompt)
+		if err == errInterrupted {
+			buf.Reset()
+			continue
+		}
+		if err != nil {
 			return
 		}
-		line := scanner.Text()
-		l := lexer.New(line)
-		p := parser.New(l)
 
-		program := p.ParseProgram()
-		if len(p.Errors()) != 0 {
-			printParserErrors(p.Errors())
+		if buf.Len() == 0 && strings.HasPrefix(line, ":") {
+			s.command(line)
+			continue
+		}
+		buf.WriteString(line)
+		buf.WriteString("\n")
+
+		input := buf.String()
+		if incomplete(input) {
 			continue
 		}
+		buf.Reset()
+
+		s.eval(input)
+	}
+}
+
+// eval evaluates input in the session environment and prints its result.
+func (s *session) eval(input string) object.Object {
+	program, ok := s.parse(input)
+	if !ok {
+		return nil
+	}
+	if !strings.HasSuffix(input, "\n") {
+		input += "\n"
+	}
+	s.transcript = append(s.transcript, input)
+
+	evaluated := s.interpreter.Eval(program, s.env)
+	s.print(evaluated)
+
+	return evaluated
+}
+
+// print writes the result of an evaluation, if there is any.
+func (s *session) print(obj object.Object) {
+	if obj == nil {
+		return
+	}
+	if obj.Type() == object.ERROR_OBJ {
+		io.WriteString(s.errOut, pretty(obj, s.color)+"\n")
+		return
+	}
+	io.WriteString(s.out, pretty(obj, s.color)+"\n")
+}
+
+// complete returns the keywords, builtins and bindings starting with prefix.
+func (s *session) complete(prefix string) []string {
+	seen := make(map[string]bool)
+	candidates := []string{}
+
+	for _, names := range [][]string{
+		token.Keywords(),
+		s.interpreter.BuiltinNames(),
+		s.env.Names(),
+	} {
+		for _, name := range names {
+			if strings.HasPrefix(name, prefix) && !seen[name] {
+				seen[name] = true
+				candidates = append(candidates, name)
+			}
+		}
+	}
+	sort.Strings(candidates)
+
+	return candidates
+}
+
+func (s *session) parse(input string) (*ast.Program, bool) {
+	l := lexer.New(input)
+	p := parser.New(l)
+
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		s.printParserErrors(p.Errors())
+		return nil, false
+	}
+	return program, true
+}
+
+// incomplete reports whether input ends in the middle of a statement, either
+// because some brace, bracket or parenthesis is still open or because a
+// string literal is unterminated. Input with more closing than opening
+// delimiters is considered complete, so that the parser can report it.
+func incomplete(input string) bool {
+	l := lexer.New(input)
+	depth := 0
+
+	for {
+		tok := l.NextToken()
 
-		evaluated := eval.Eval(program, env)
-		if evaluated != nil {
-			io.WriteString(out, evaluated.Inspect())
-			io.WriteString(out, "\n")
+		switch tok.Type {
+		case token.LBRACE, token.LBRACKET, token.LPAREN:
+			depth++
+		case token.RBRACE, token.RBRACKET, token.RPAREN:
+			depth--
+			if depth < 0 {
+				return false
+			}
+		case token.ILLEGAL:
+			if strings.HasPrefix(tok.Literal, `"`) {
+				return true
+			}
+		case token.EOF:
+			return depth > 0
 		}
 	}
 }
 
-func printParserErrors(errors []string) {
-	io.WriteString(os.Stderr, " Parser errors:\n")
+func (s *session) printParserErrors(errors []string) {
+	io.WriteString(s.errOut, " Parser errors:\n")
 	for _, msg := range errors {
-		io.WriteString(os.Stderr, "\t"+msg+"\n")
+		io.WriteString(s.errOut, "\t"+msg+"\n")
 	}
 }
@@ -0,0 +1,180 @@
+package repl
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+)
+
+func TestIncomplete(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected bool
+	}{
+		{"let x = 5;", false},
+		{"let add = fn(a, b) {", true},
+		{"let add = fn(a, b) {\n a + b\n", true},
+		{"let add = fn(a, b) {\n a + b\n};", false},
+		{"[1, 2,", true},
+		{"{\"a\": [1, 2]", true},
+		{"add(1,", true},
+		{"add(1, 2))", false},
+		{"}", false},
+		{"let s = \"foo", true},
+		{"let s = \"foo\nbar\";", false},
+		{"\"{\"", false},
+	} {
+		if got := incomplete(tt.input); got != tt.expected {
+			t.Errorf("incomplete(%q) = %t, expected %t",
+				tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestCommands(t *testing.T) {
+	var out bytes.Buffer
+	s := newSession(&out)
+
+	s.eval("let x = 5;")
+	s.eval(`let name = "monkey";`)
+
+	for _, tt := range []struct {
+		line     string
+		expected string
+	}{
+		{":env", "name = monkey\nx = 5\n"},
+		{":ast 1 + 2 * x", "(1 + (2 * x))\n"},
+		{":tokens x + 1", "IDENT\t\"x\"\n+\t\"+\"\nINT\t\"1\"\n"},
+		{":type [x]", "ARRAY\n"},
+		{":type x", "INTEGER\n"},
+		{":ast", "usage: :ast <expr>\n"},
+		{":nope", "unknown command :nope, see :help\n"},
+		{":reset", ""},
+		{":env", ""},
+	} {
+		out.Reset()
+		s.command(tt.line)
+
+		if out.String() != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.line, tt.expected, out.String())
+		}
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	var out bytes.Buffer
+	path := filepath.Join(t.TempDir(), "session.mk")
+
+	s := newSession(&out)
+	s.eval("let a = 1;")
+	s.eval("let b = a + 1;")
+	s.command(":save " + path)
+
+	s = newSession(&out)
+	s.command(":load " + path)
+
+	out.Reset()
+	s.command(":env")
+
+	expected := "a = 1\nb = 2\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestStart(t *testing.T) {
+	var out, errOut bytes.Buffer
+
+	input := `let add = fn(a, b) {
+	a + b
+};
+println(add(1, 2));
+add(1, true)
+let x = ;
+"done"
+`
+	Start(strings.NewReader(input), &out, WithErrorWriter(&errOut))
+
+	if expected := "3\nnull\ndone\n"; out.String() != expected {
+		t.Errorf("wrong output. Expected %q, got %q", expected, out.String())
+	}
+	expected := "Error: type mismatch: INTEGER + BOOLEAN\n" +
+		" Parser errors:\n\t1:9: No prefix parse function for ';' found.\n"
+	if errOut.String() != expected {
+		t.Errorf("wrong errors. Expected %q, got %q", expected, errOut.String())
+	}
+}
+
+func TestPretty(t *testing.T) {
+	s := newSession(io.Discard)
+
+	for _, tt := range []struct {
+		input    string
+		color    bool
+		expected string
+	}{
+		{`"plain"`, false, "plain"},
+		{`[1, "two", true]`, false, `[1, "two", true]`},
+		{`{"b": [1], "a": {}}`, false, `{"b": [1], "a": {}}`},
+		{
+			`[1, "two", true]`,
+			true,
+			"[\x1b[36m1\x1b[0m, \x1b[32m\"two\"\x1b[0m, \x1b[33mtrue\x1b[0m]",
+		},
+		{
+			`["a long string that spans", ["most of the line", "and more"], 3]`,
+			false,
+			`[
+  "a long string that spans",
+  ["most of the line", "and more"],
+  3
+]`,
+		},
+		{
+			`{"key": ["a long string that spans", "most of the line", "and more"]}`,
+			false,
+			`{
+  "key": [
+    "a long string that spans",
+    "most of the line",
+    "and more"
+  ]
+}`,
+		},
+	} {
+		evaluated := s.interpreter.Eval(mustParse(t, tt.input), s.env)
+
+		if got := pretty(evaluated, tt.color); got != tt.expected {
+			t.Errorf("%s: expected\n%s\ngot\n%s", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestPrettyEscapesStrings(t *testing.T) {
+	arr := &object.Array{Elements: []object.Object{
+		&object.String{Value: "say \"hi\"\n"},
+		&object.String{Value: "\x1b[2Jé"},
+	}}
+
+	expected := `["say \"hi\"\n", "\x1b[2Jé"]`
+	if got := pretty(arr, false); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
+
+func mustParse(t *testing.T, input string) *ast.Program {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors: %v", p.Errors())
+	}
+	return program
+}
@@ -1,22 +1,42 @@
 package token
 
+import "sort"
+
 type TokenType string
 
 type Token struct {
 	Type    TokenType
 	Literal string
+	// Line and Column locate the first character of the token in the
+	// source, both starting at 1.
+	Line   int
+	Column int
 }
 
 var keywords = map[string]TokenType{
 	"else":   ELSE,
+	"export": EXPORT,
 	"false":  FALSE,
 	"fn":     FUNCTION,
 	"if":     IF,
+	"import": IMPORT,
 	"let":    LET,
+	"macro":  MACRO,
 	"return": RETURN,
 	"true":   TRUE,
 }
 
+// Keywords returns every reserved word of the language, in sorted order.
+func Keywords() []string {
+	words := make([]string, 0, len(keywords))
+	for word := range keywords {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+
+	return words
+}
+
 func LookupIdent(ident string) TokenType {
 	if tok, ok := keywords[ident]; ok {
 		return tok
@@ -46,16 +66,22 @@ const (
 	GT     = ">"
 	EQ     = "=="
 	NEQ    = "!="
+	ARROW  = "->"
 	// Identifiers and literals.
 	IDENT  = "IDENT"
 	INT    = "INT"
 	STRING = "STRING"
+	// Comments, which are not part of the token stream.
+	COMMENT = "COMMENT"
 	// Keywords.
 	ELSE     = "ELSE"
+	EXPORT   = "EXPORT"
 	FALSE    = "FALSE"
 	FUNCTION = "FUNCTION"
 	IF       = "IF"
+	IMPORT   = "IMPORT"
 	LET      = "LET"
+	MACRO    = "MACRO"
 	RETURN   = "RETURN"
 	TRUE     = "TRUE"
 	// Illegal.
@@ -0,0 +1,120 @@
+package types
+
+// builtins returns the types of the builtins. Those taking arguments of
+// several kinds, such as either arrays or strings, take any, and those
+// returning values of several kinds return any. Builtins not listed are
+// left unchecked, as if they were undefined.
+func builtins() map[string]*scheme {
+	a, b := &Var{}, &Var{}
+	k, v := &Var{}, &Var{}
+
+	generic := func(t Type, vars ...*Var) *scheme {
+		return &scheme{vars: vars, t: t}
+	}
+	fn := func(result Type, params ...Type) *Function {
+		return &Function{Params: params, Return: result}
+	}
+	variadic := func(result Type) *scheme {
+		return monotype(&Function{Return: result, Variadic: true})
+	}
+	// optional makes the last n parameters of f optional.
+	optional := func(n int, f *Function) *Function {
+		f.Optional = n
+		return f
+	}
+	arrayA := &Array{Element: a}
+	hashKV := &Hash{Key: k, Value: v}
+	pairs := &Array{Element: &Array{Element: Any}}
+
+	types := map[string]*scheme{
+		"len":     monotype(fn(Int, Any)),
+		"str":     monotype(fn(String, Any)),
+		"type":    monotype(fn(String, Any)),
+		"int":     monotype(fn(Int, Any)),
+		"bool":    monotype(fn(Bool, Any)),
+		"println": variadic(Null),
+		"min":     variadic(Int),
+		"max":     variadic(Int),
+
+		"head":    generic(fn(a, arrayA), a),
+		"last":    generic(fn(a, arrayA), a),
+		"tail":    generic(fn(arrayA, arrayA), a),
+		"reverse": generic(fn(arrayA, arrayA), a),
+		"unique":  generic(fn(arrayA, arrayA), a),
+		"append":  generic(fn(arrayA, arrayA, a), a),
+		"map":     generic(fn(&Array{Element: b}, arrayA, fn(b, a)), a, b),
+		"filter":  generic(fn(arrayA, arrayA, fn(Any, a)), a),
+		"find":    generic(fn(a, arrayA, fn(Any, a)), a),
+		"any":     generic(fn(Bool, arrayA, fn(Any, a)), a),
+		"all":     generic(fn(Bool, arrayA, fn(Any, a)), a),
+		"each":    generic(fn(Null, arrayA, fn(Any, a)), a),
+		"reduce":  generic(fn(b, arrayA, fn(b, b, a), b), a, b),
+		"sort":    generic(optional(1, fn(arrayA, arrayA, fn(Any, a, a))), a),
+		"shuffle": generic(fn(arrayA, arrayA), a),
+		"zip":     generic(fn(pairs, arrayA, &Array{Element: b}), a, b),
+		"flatten": monotype(fn(&Array{Element: Any}, &Array{Element: Any})),
+		"range":   monotype(optional(2, fn(&Array{Element: Int}, Int, Int, Int))),
+
+		"random_int":    monotype(fn(Int, Int, Int)),
+		"random_choice": generic(fn(a, arrayA), a),
+
+		"keys":       generic(fn(&Array{Element: k}, hashKV), k, v),
+		"values":     generic(fn(&Array{Element: v}, hashKV), k, v),
+		"items":      generic(fn(pairs, hashKV), k, v),
+		"has":        generic(fn(Bool, hashKV, k), k, v),
+		"get":        generic(optional(1, fn(v, hashKV, k, v)), k, v),
+		"set":        generic(fn(hashKV, hashKV, k, v), k, v),
+		"delete":     generic(fn(hashKV, hashKV, k), k, v),
+		"from_pairs": monotype(fn(&Hash{Key: Any, Value: Any}, pairs)),
+		// Hashes merged may have keys and values of different types.
+		"merge": variadic(&Hash{Key: Any, Value: Any}),
+
+		"split":       monotype(fn(&Array{Element: String}, String, String)),
+		"join":        monotype(fn(String, &Array{Element: String}, String)),
+		"trim":        monotype(fn(String, String)),
+		"upper":       monotype(fn(String, String)),
+		"lower":       monotype(fn(String, String)),
+		"chars":       monotype(fn(&Array{Element: String}, String)),
+		"contains":    monotype(fn(Bool, String, String)),
+		"starts_with": monotype(fn(Bool, String, String)),
+		"ends_with":   monotype(fn(Bool, String, String)),
+		"index_of":    monotype(fn(Int, String, String)),
+		"replace":     monotype(fn(String, String, String, String)),
+		"repeat":      monotype(fn(String, String, Int)),
+		"pad_left":    monotype(optional(1, fn(String, String, Int, String))),
+		"pad_right":   monotype(optional(1, fn(String, String, Int, String))),
+
+		"regex_match":    monotype(fn(Any, String, String)),
+		"regex_find_all": monotype(fn(&Array{Element: Any}, String, String)),
+		"regex_replace":  monotype(fn(String, String, String, String)),
+		"regex_split":    monotype(fn(&Array{Element: String}, String, String)),
+
+		"json_parse":     monotype(fn(Any, String)),
+		"json_stringify": monotype(optional(1, fn(String, Any, Any))),
+
+		"abs":   monotype(fn(Int, Int)),
+		"sqrt":  monotype(fn(Int, Int)),
+		"pow":   monotype(fn(Int, Int, Int)),
+		"gcd":   monotype(fn(Int, Int, Int)),
+		"clamp": monotype(fn(Int, Int, Int, Int)),
+		"floor": monotype(optional(1, fn(Int, Int, Int))),
+		"ceil":  monotype(optional(1, fn(Int, Int, Int))),
+
+		"read_file":  monotype(fn(String, String)),
+		"read_lines": monotype(fn(&Array{Element: String}, String)),
+		"write_file": monotype(fn(Null, String, String)),
+		"list_dir":   monotype(fn(&Array{Element: String}, String)),
+		"exists":     monotype(fn(Bool, String)),
+
+		"now":             monotype(fn(Int)),
+		"sleep":           monotype(fn(Null, Int)),
+		"duration":        monotype(fn(Int, String)),
+		"format_duration": monotype(fn(String, Int)),
+		"format_time":     monotype(optional(1, fn(String, Int, String))),
+		"parse_time":      monotype(optional(1, fn(Int, String, String))),
+	}
+	for _, name := range []string{"is_int", "is_bool", "is_string", "is_array", "is_hash", "is_fn", "is_null"} {
+		types[name] = monotype(fn(Bool, Any))
+	}
+	return types
+}
@@ -0,0 +1,626 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+
+	"monkey/ast"
+	"monkey/token"
+)
+
+// Check returns the type errors found in program, in the order they appear.
+func Check(program *ast.Program) []*Error {
+	_, errors := Infer(program)
+	return errors
+}
+
+// Infer returns the types of the bindings made at the top level of program,
+// along with the type errors found in it. A binding made several times has
+// the type of the last one.
+func Infer(program *ast.Program) (map[string]Type, []*Error) {
+	globals := &scope{
+		names: make(map[string]*scheme),
+		outer: &scope{names: builtins()},
+	}
+	c := &checker{
+		scope:    globals,
+		globals:  globals,
+		macros:   make(map[string]bool),
+		declared: make(map[string]*Var),
+	}
+	c.program(program)
+
+	result := make(map[string]Type)
+	for name, s := range globals.names {
+		result[name] = s.t
+	}
+	return result, c.errors
+}
+
+// scheme is the type of a binding, in which vars stand for any type, so
+// that every use of the binding can choose a different one.
+type scheme struct {
+	vars []*Var
+	t    Type
+}
+
+func monotype(t Type) *scheme {
+	return &scheme{t: t}
+}
+
+// scope holds the bindings of a function, or of the top level. The blocks of
+// if expressions share the scope they appear in, as they do when evaluated.
+type scope struct {
+	names map[string]*scheme
+	outer *scope
+}
+
+func (s *scope) lookup(name string) (*scheme, bool) {
+	for ; s != nil; s = s.outer {
+		if sch, ok := s.names[name]; ok {
+			return sch, true
+		}
+	}
+	return nil, false
+}
+
+// sum is an addition whose operands are of type t.
+type sum struct {
+	node *ast.InfixExpression
+	t    Type
+}
+
+type checker struct {
+	unifier
+
+	scope   *scope
+	globals *scope
+	// level is the depth of the let bindings being checked.
+	level int
+	// returns holds the return types of the enclosing functions, innermost
+	// last.
+	returns []Type
+	// macros holds the names of the macros defined at the top level, whose
+	// calls are replaced before the program runs.
+	macros map[string]bool
+	// declared holds the types given to top-level bindings ahead of their
+	// let statements, for the functions using them before they are made.
+	declared map[string]*Var
+	// sums holds the additions whose operands were unknown when checked,
+	// which must turn out to be integers or strings.
+	sums []sum
+
+	errors []*Error
+}
+
+func (c *checker) errorf(tok token.Token, format string, a ...interface{}) {
+	c.errors = append(c.errors, &Error{
+		Line:    tok.Line,
+		Column:  tok.Column,
+		Message: fmt.Sprintf(format, a...),
+	})
+}
+
+func (c *checker) fresh() *Var {
+	return &Var{level: c.level}
+}
+
+func (c *checker) program(program *ast.Program) {
+	for _, stmt := range program.Statements {
+		let := topLevelLet(stmt)
+		if let == nil {
+			continue
+		}
+		if _, ok := let.Value.(*ast.MacroLiteral); ok {
+			c.macros[let.Name.Value] = true
+			continue
+		}
+		if _, ok := c.declared[let.Name.Value]; !ok {
+			v := c.fresh()
+			c.declared[let.Name.Value] = v
+			c.scope.names[let.Name.Value] = monotype(v)
+		}
+	}
+
+	c.block(program.Statements, false)
+
+	for _, sum := range c.sums {
+		t := resolve(sum.t)
+		if _, ok := t.(*Var); ok || t == Int || t == String || t == Any {
+			continue
+		}
+		c.errorf(ast.StartToken(sum.node), "unknown operation: %s + %s", t, t)
+	}
+	sort.SliceStable(c.errors, func(i, j int) bool {
+		a, b := c.errors[i], c.errors[j]
+		return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+	})
+}
+
+func topLevelLet(stmt ast.Statement) *ast.LetStatement {
+	switch stmt := stmt.(type) {
+	case *ast.LetStatement:
+		return stmt
+	case *ast.ExportStatement:
+		return stmt.Statement
+	default:
+		return nil
+	}
+}
+
+// block checks stmts and returns the type of the value of the last one,
+// which is only used if used is set. Statements after a return are never
+// run, and so are not checked.
+func (c *checker) block(stmts []ast.Statement, used bool) Type {
+	var result Type = Null
+	for i, stmt := range stmts {
+		result = c.statement(stmt, used && i == len(stmts)-1)
+		if _, ok := stmt.(*ast.ReturnStatement); ok {
+			break
+		}
+	}
+	return result
+}
+
+func (c *checker) statement(stmt ast.Statement, used bool) Type {
+	switch stmt := stmt.(type) {
+	case *ast.LetStatement:
+		c.let(stmt)
+		return Null
+	case *ast.ExportStatement:
+		c.let(stmt.Statement)
+		return Null
+	case *ast.ReturnStatement:
+		t := c.expression(stmt.ReturnValue, true)
+		if len(c.returns) > 0 {
+			result := c.returns[len(c.returns)-1]
+			c.expect(result, t, stmt.ReturnValue)
+			settle(result, t)
+		}
+		// Nothing follows a return, so its value can be of any type.
+		return c.fresh()
+	case *ast.ExpressionStatement:
+		return c.expression(stmt.Expression, used)
+	default:
+		return Any
+	}
+}
+
+func (c *checker) let(let *ast.LetStatement) {
+	if let == nil || let.Name == nil {
+		return
+	}
+	name := let.Name.Value
+	_, isFunction := let.Value.(*ast.FunctionLiteral)
+	_, isAlias := let.Value.(*ast.Identifier)
+
+	c.level++
+	var self *Var
+	if isFunction {
+		// Functions may call themselves, but only with the same types.
+		self = c.fresh()
+		c.scope.names[name] = monotype(self)
+	}
+	t := c.expression(let.Value, true)
+	if self != nil {
+		c.expect(self, t, let.Value)
+	}
+	if let.Type != nil {
+		annotated := c.annotation(let.Type)
+		if c.expect(annotated, t, let.Value) {
+			t = annotated
+		}
+	}
+	c.level--
+
+	// Only functions and other names for bindings are generalized, as other
+	// values may be arrays and hashes whose elements are only known later.
+	s := monotype(t)
+	if isFunction || isAlias {
+		s = c.generalize(t)
+	}
+	c.scope.names[name] = s
+
+	if v, ok := c.declared[name]; ok && c.scope == c.globals {
+		delete(c.declared, name)
+		if !c.unify(v, c.instantiate(s)) {
+			c.errorf(let.Name.Token, "type mismatch: %s is used as %s, but is %s", name, v, s.t)
+		}
+	}
+}
+
+// expect checks that the type got of node is the one expected, and reports
+// whether it is.
+func (c *checker) expect(expected, got Type, node ast.Expression) bool {
+	if c.unify(expected, got) {
+		return true
+	}
+	c.errorf(ast.StartToken(node), "type mismatch: expected %s, got %s", expected, got)
+	return false
+}
+
+// expression returns the type of e. Unless used is set, the value of e is
+// thrown away, and the branches of an if are allowed to differ.
+func (c *checker) expression(e ast.Expression, used bool) Type {
+	switch e := e.(type) {
+	case *ast.IntegerLiteral:
+		return Int
+	case *ast.StringLiteral:
+		return String
+	case *ast.Boolean:
+		return Bool
+	case *ast.Identifier:
+		if s, ok := c.scope.lookup(e.Value); ok {
+			return c.instantiate(s)
+		}
+		// Undefined identifiers are left for the linter to report.
+		return Any
+	case *ast.PrefixExpression:
+		return c.prefix(e)
+	case *ast.InfixExpression:
+		return c.infix(e)
+	case *ast.IfExpression:
+		return c.ifExpression(e, used)
+	case *ast.FunctionLiteral:
+		return c.function(e)
+	case *ast.CallExpression:
+		return c.call(e)
+	case *ast.ArrayLiteral:
+		return &Array{Element: c.elements(e.Elements)}
+	case *ast.HashLiteral:
+		return c.hash(e)
+	case *ast.IndexExpression:
+		return c.index(e)
+	default:
+		// Macros, imported modules and anything the parser gave up on.
+		return Any
+	}
+}
+
+func (c *checker) prefix(e *ast.PrefixExpression) Type {
+	right := c.expression(e.Right, true)
+
+	switch e.Operator {
+	case "-":
+		if !c.unify(Int, right) {
+			c.errorf(ast.StartToken(e), "unknown operation: -%s", right)
+		}
+		return Int
+	case "!":
+		return Bool
+	default:
+		return Any
+	}
+}
+
+func (c *checker) infix(e *ast.InfixExpression) Type {
+	left := c.expression(e.Left, true)
+	right := c.expression(e.Right, true)
+
+	switch e.Operator {
+	case "==", "!=":
+		return Bool
+	case "+":
+		return c.addition(e, left, right)
+	case "-", "*", "/":
+		c.integers(e, left, right)
+		return Int
+	case "<", ">":
+		c.integers(e, left, right)
+		return Bool
+	default:
+		return Any
+	}
+}
+
+// addition checks an addition, which applies to integers and strings.
+func (c *checker) addition(e *ast.InfixExpression, left, right Type) Type {
+	l, r := resolve(left), resolve(right)
+	if l == Any || r == Any {
+		if l == Any {
+			l, r = r, l
+		}
+		if l == Int || l == String {
+			return l
+		}
+		return Any
+	}
+
+	if !c.unify(l, r) {
+		c.mismatch(e, l, r)
+		return Any
+	}
+	switch t := resolve(l).(type) {
+	case *Var:
+		c.sums = append(c.sums, sum{node: e, t: t})
+		return t
+	default:
+		if t != Int && t != String {
+			c.errorf(ast.StartToken(e), "unknown operation: %s + %s", t, t)
+			return Any
+		}
+		return t
+	}
+}
+
+// integers checks an operation applying only to integers.
+func (c *checker) integers(e *ast.InfixExpression, left, right Type) {
+	l, r := resolve(left), resolve(right)
+	if c.unify(Int, l) && c.unify(Int, r) {
+		return
+	}
+	c.mismatch(e, l, r)
+}
+
+// mismatch reports an operation applied to operands of the wrong types, the
+// same way as evaluating it would.
+func (c *checker) mismatch(e *ast.InfixExpression, left, right Type) {
+	if left.String() == right.String() {
+		c.errorf(ast.StartToken(e), "unknown operation: %s %s %s", left, e.Operator, right)
+		return
+	}
+	c.errorf(ast.StartToken(e), "type mismatch: %s %s %s", left, e.Operator, right)
+}
+
+func (c *checker) ifExpression(e *ast.IfExpression, used bool) Type {
+	c.expression(e.Condition, true)
+
+	consequence := c.block(e.Consequence.Statements, used)
+	if e.Alternative == nil {
+		return consequence
+	}
+	alternative := c.block(e.Alternative.Statements, used)
+	if !used {
+		return Null
+	}
+
+	// Branches of different types give a value of either, like the elements
+	// of an array mixing types.
+	if !c.unify(consequence, alternative) {
+		return Any
+	}
+	return consequence
+}
+
+func (c *checker) function(fn *ast.FunctionLiteral) Type {
+	outer := c.scope
+	c.scope = &scope{names: make(map[string]*scheme), outer: outer}
+	defer func() { c.scope = outer }()
+
+	params := make([]Type, len(fn.Parameters))
+	for i, param := range fn.Parameters {
+		if t := fn.ParameterType(i); t != nil {
+			params[i] = c.annotation(t)
+		} else {
+			params[i] = c.fresh()
+		}
+		c.scope.names[param.Value] = monotype(params[i])
+	}
+	var result Type = c.fresh()
+	if fn.ReturnType != nil {
+		result = c.annotation(fn.ReturnType)
+	}
+
+	c.returns = append(c.returns, result)
+	body := c.block(fn.Body.Statements, true)
+	c.returns = c.returns[:len(c.returns)-1]
+
+	if !c.unify(result, body) {
+		at := fn.Body.Rbrace
+		if n := len(fn.Body.Statements); n > 0 {
+			at = ast.StartToken(fn.Body.Statements[n-1])
+		}
+		c.errorf(at, "type mismatch: expected %s, got %s", result, body)
+	}
+	settle(result, body)
+	return &Function{Params: params, Return: result}
+}
+
+// settle makes the result of a function any if it is still unknown and one
+// of the values it returns is of type any. Unifying leaves it unknown, and
+// it would otherwise be generalized, as if the function could return
+// whatever its callers want.
+func settle(result, value Type) {
+	if resolve(value) != Any {
+		return
+	}
+	if v, ok := resolve(result).(*Var); ok {
+		v.bound = Any
+	}
+}
+
+func (c *checker) call(call *ast.CallExpression) Type {
+	if ident, ok := call.Function.(*ast.Identifier); ok {
+		// Quoted code is not run, and the arguments of macros are quoted.
+		if ident.Value == "quote" || c.macros[ident.Value] {
+			return Any
+		}
+	}
+
+	fn := c.expression(call.Function, true)
+	args := make([]Type, len(call.Arguments))
+	for i, arg := range call.Arguments {
+		args[i] = c.expression(arg, true)
+	}
+
+	switch f := resolve(fn).(type) {
+	case *Function:
+		if f.Variadic {
+			return f.Return
+		}
+		if least := len(f.Params) - f.Optional; len(args) < least || len(args) > len(f.Params) {
+			expected := fmt.Sprint(len(f.Params))
+			switch f.Optional {
+			case 0:
+			case 1:
+				expected = fmt.Sprintf("%d or %d", least, len(f.Params))
+			default:
+				expected = fmt.Sprintf("%d to %d", least, len(f.Params))
+			}
+			c.errorf(ast.StartToken(call), "wrong number of arguments, expected %s, got %d",
+				expected, len(args))
+			return f.Return
+		}
+		for i, arg := range call.Arguments {
+			c.expect(f.Params[i], args[i], arg)
+		}
+		return f.Return
+	case *Var:
+		result := c.fresh()
+		c.expect(f, &Function{Params: args, Return: result}, call.Function)
+		return result
+	default:
+		if f != Any {
+			c.errorf(ast.StartToken(call), "not a function: %s", f)
+		}
+		return Any
+	}
+}
+
+// elements returns the type of the elements of an array, which is any if
+// they differ.
+func (c *checker) elements(elements []ast.Expression) Type {
+	var result Type = c.fresh()
+	for _, e := range elements {
+		if t := c.expression(e, true); !c.unify(result, t) {
+			result = Any
+		}
+	}
+	return result
+}
+
+func (c *checker) hash(hash *ast.HashLiteral) Type {
+	var key, value Type = c.fresh(), c.fresh()
+	for _, k := range hash.Keys {
+		kt := c.expression(k, true)
+		switch resolve(kt).(type) {
+		case *Array, *Hash, *Function:
+			c.errorf(ast.StartToken(k), "invalid as hash key: %s", kt)
+		}
+		if !c.unify(key, kt) {
+			key = Any
+		}
+		if vt := c.expression(hash.Pairs[k], true); !c.unify(value, vt) {
+			value = Any
+		}
+	}
+	return &Hash{Key: key, Value: value}
+}
+
+func (c *checker) index(e *ast.IndexExpression) Type {
+	left := c.expression(e.Left, true)
+	index := c.expression(e.Index, true)
+
+	switch l := resolve(left).(type) {
+	case *Array:
+		c.expect(Int, index, e.Index)
+		return l.Element
+	case *Hash:
+		c.expect(l.Key, index, e.Index)
+		return l.Value
+	case *Var:
+		// Either an array or a hash.
+		return Any
+	default:
+		if l != Any {
+			c.errorf(ast.StartToken(e), "invalid index operator: %s", l)
+		}
+		return Any
+	}
+}
+
+// annotation returns the type written as t.
+func (c *checker) annotation(t ast.TypeExpr) Type {
+	switch t := t.(type) {
+	case *ast.NamedType:
+		if b, ok := basics[t.Name]; ok {
+			return b
+		}
+		c.errorf(t.Token, "unknown type %s", t.Name)
+		return Any
+	case *ast.ArrayType:
+		return &Array{Element: c.annotation(t.Element)}
+	case *ast.HashType:
+		return &Hash{Key: c.annotation(t.Key), Value: c.annotation(t.Value)}
+	case *ast.FunctionType:
+		params := make([]Type, len(t.Parameters))
+		for i, p := range t.Parameters {
+			params[i] = c.annotation(p)
+		}
+		return &Function{Params: params, Return: c.annotation(t.Return)}
+	default:
+		return Any
+	}
+}
+
+// generalize returns the scheme of a binding of type t, whose variables
+// introduced within the binding stand for any type.
+func (c *checker) generalize(t Type) *scheme {
+	s := &scheme{t: t}
+	seen := make(map[*Var]bool)
+
+	var visit func(t Type)
+	visit = func(t Type) {
+		switch t := resolve(t).(type) {
+		case *Var:
+			if t.level > c.level && !seen[t] {
+				seen[t] = true
+				s.vars = append(s.vars, t)
+			}
+		case *Array:
+			visit(t.Element)
+		case *Hash:
+			visit(t.Key)
+			visit(t.Value)
+		case *Function:
+			for _, p := range t.Params {
+				visit(p)
+			}
+			visit(t.Return)
+		}
+	}
+	visit(t)
+
+	return s
+}
+
+// instantiate returns the type of a use of a binding of scheme s, with new
+// variables in place of those of s.
+func (c *checker) instantiate(s *scheme) Type {
+	if len(s.vars) == 0 {
+		return s.t
+	}
+	vars := make(map[*Var]Type, len(s.vars))
+	for _, v := range s.vars {
+		vars[v] = c.fresh()
+	}
+
+	var replace func(t Type) Type
+	replace = func(t Type) Type {
+		switch t := resolve(t).(type) {
+		case *Var:
+			if r, ok := vars[t]; ok {
+				return r
+			}
+			return t
+		case *Array:
+			return &Array{Element: replace(t.Element)}
+		case *Hash:
+			return &Hash{Key: replace(t.Key), Value: replace(t.Value)}
+		case *Function:
+			params := make([]Type, len(t.Params))
+			for i, p := range t.Params {
+				params[i] = replace(p)
+			}
+			return &Function{
+				Params:   params,
+				Return:   replace(t.Return),
+				Variadic: t.Variadic,
+				Optional: t.Optional,
+			}
+		default:
+			return t
+		}
+	}
+	return replace(s.t)
+}
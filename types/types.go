@@ -0,0 +1,159 @@
+// Package types checks programs for type errors ahead of evaluation, such as
+// subtracting an integer from a string, which would otherwise only show up
+// when the code runs.
+//
+// Types are inferred in the manner of Hindley–Milner, so that unannotated
+// code is checked as well as code carrying annotations. Functions bound by
+// let are generalized, so that a function like fn(x) { x } can be used with
+// arguments of any type.
+//
+// The language is dynamically typed, and some of what it allows cannot be
+// described by the types of the checker. Arrays and hashes mixing elements
+// of different types, if expressions whose branches differ, undefined
+// identifiers, imported modules, and quoted code are given the type any,
+// which is compatible with every other type, rather than being reported.
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Type is the type of a value, as inferred by the checker.
+type Type interface {
+	String() string
+	typ()
+}
+
+// Basic is a type without parameters, such as int.
+type Basic struct {
+	Name string
+}
+
+// The basic types. Any is the type of values the checker knows nothing about,
+// which is compatible with every other type.
+var (
+	Int    = &Basic{"int"}
+	Bool   = &Basic{"bool"}
+	String = &Basic{"string"}
+	Null   = &Basic{"null"}
+	Any    = &Basic{"any"}
+)
+
+var basics = map[string]*Basic{
+	"int":    Int,
+	"bool":   Bool,
+	"string": String,
+	"null":   Null,
+	"any":    Any,
+}
+
+// Array is the type of arrays whose elements are of type Element.
+type Array struct {
+	Element Type
+}
+
+// Hash is the type of hashes from Key to Value.
+type Hash struct {
+	Key   Type
+	Value Type
+}
+
+// Function is the type of functions taking Params and returning Return.
+// Variadic functions take any number of arguments of any type, and the last
+// Optional parameters of a function may be left out. Both are only found
+// among builtins.
+type Function struct {
+	Params   []Type
+	Return   Type
+	Variadic bool
+	Optional int
+}
+
+// Var is a type not known yet, which is found out as the program is checked.
+// Those still unknown once it is are type parameters, standing for any type.
+type Var struct {
+	// bound is the type the variable stands for, once known.
+	bound Type
+	// level is the depth of let bindings the variable was introduced at.
+	// Only variables introduced within the value of a binding are made
+	// parameters of its type.
+	level int
+}
+
+func (t *Basic) typ()    {}
+func (t *Array) typ()    {}
+func (t *Hash) typ()     {}
+func (t *Function) typ() {}
+func (t *Var) typ()      {}
+
+func (t *Basic) String() string    { return t.Name }
+func (t *Array) String() string    { return typeString(t, map[*Var]string{}) }
+func (t *Hash) String() string     { return typeString(t, map[*Var]string{}) }
+func (t *Function) String() string { return typeString(t, map[*Var]string{}) }
+func (t *Var) String() string      { return typeString(t, map[*Var]string{}) }
+
+// typeString writes t, naming the unknown types found in it a, b, c and so
+// on, in the order they appear.
+func typeString(t Type, names map[*Var]string) string {
+	switch t := resolve(t).(type) {
+	case *Basic:
+		return t.Name
+	case *Array:
+		return "[" + typeString(t.Element, names) + "]"
+	case *Hash:
+		return "{" + typeString(t.Key, names) + ": " + typeString(t.Value, names) + "}"
+	case *Function:
+		if t.Variadic {
+			return "fn(...) -> " + typeString(t.Return, names)
+		}
+		params := make([]string, len(t.Params))
+		for i, p := range t.Params {
+			params[i] = typeString(p, names)
+			if i >= len(t.Params)-t.Optional {
+				params[i] += "?"
+			}
+		}
+		return "fn(" + strings.Join(params, ", ") + ") -> " + typeString(t.Return, names)
+	case *Var:
+		if name, ok := names[t]; ok {
+			return name
+		}
+		name := varName(len(names))
+		names[t] = name
+		return name
+	default:
+		return "?"
+	}
+}
+
+func varName(n int) string {
+	name := string(rune('a' + n%26))
+	if n >= 26 {
+		name += strings.Repeat("'", n/26)
+	}
+	return name
+}
+
+// resolve returns the type t stands for, following the variables already
+// known.
+func resolve(t Type) Type {
+	for {
+		v, ok := t.(*Var)
+		if !ok || v.bound == nil {
+			return t
+		}
+		t = v.bound
+	}
+}
+
+// Error is a type error found in a program.
+type Error struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+}
@@ -0,0 +1,154 @@
+package types
+
+import (
+	"testing"
+
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/parser"
+)
+
+func TestInfer(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		name     string
+		expected string
+	}{
+		{"let x = 1;", "x", "int"},
+		{`let s = "a" + "b";`, "s", "string"},
+		{"let b = 1 < 2;", "b", "bool"},
+		{"let xs = [1, 2, 3];", "xs", "[int]"},
+		{`let xs = [1, "a"];`, "xs", "[any]"},
+		{"let xs = [];", "xs", "[a]"},
+		{`let h = {"a": 1};`, "h", "{string: int}"},
+		{"let id = fn(x) { x };", "id", "fn(a) -> a"},
+		{"let add = fn(a, b) { a - b };", "add", "fn(int, int) -> int"},
+		{"let k = fn(a, b) { a };", "k", "fn(a, b) -> a"},
+		{"let apply = fn(f, x) { f(x) };", "apply", "fn(fn(a) -> b, a) -> b"},
+		{"let compose = fn(f, g) { fn(x) { f(g(x)) } };", "compose",
+			"fn(fn(a) -> b, fn(c) -> a) -> fn(c) -> b"},
+		{"let fact = fn(n) { if (n < 2) { 1 } else { n * fact(n - 1) } };", "fact",
+			"fn(int) -> int"},
+		{"let f = fn(n) { if (n < 0) { return 0; }; n };", "f", "fn(int) -> int"},
+		{"let f = fn(x) { let y = x; y };", "f", "fn(a) -> a"},
+		{"let first = fn(xs) { xs[0] };", "first", "fn(a) -> any"},
+		{"let first = fn(xs: [string]) { xs[0] };", "first", "fn([string]) -> string"},
+		{"let lengths = fn(xs) { map(xs, len) };", "lengths", "fn([a]) -> [int]"},
+		{`let names = map([1, 2], fn(x) { str(x) });`, "names", "[string]"},
+		{"let total = reduce([1, 2], fn(acc, x) { acc + x }, 0);", "total", "int"},
+		{"let f = fn() { g(1) }; let g = fn(x) { x * 2 };", "f", "fn() -> int"},
+		{"let x: int = 1;", "x", "int"},
+		{"let f = fn(s: string) -> bool { true };", "f", "fn(string) -> bool"},
+		{"let f: fn([int]) -> int = fn(xs) { len(xs) };", "f", "fn([int]) -> int"},
+		{"let f = fn(x) { x + 1 };", "f", "fn(int) -> int"},
+		{"let f = fn(x, y) { x + y };", "f", "fn(a, a) -> a"},
+		{"let m = import \"m\";", "m", "any"},
+		{"let v = if (true) { 1 };", "v", "int"},
+		{"let p = fn(x) { println(x) };", "p", "fn(a) -> null"},
+		{"let v = if (true) { 1 } else { \"a\" };", "v", "any"},
+		{"let f = fn(x) { if (x > 0) { \"pos\" } else { 0 } };", "f", "fn(int) -> any"},
+		{"let f = fn() { let a = 1; return a; let b = 2; };", "f", "fn() -> int"},
+		{"let id = fn(x) { x }; let g = id;", "g", "fn(a) -> a"},
+		{"let r = range(3);", "r", "[int]"},
+		{"let s = sort([3, 1], fn(a, b) { a < b });", "s", "[int]"},
+		{`let g = get({"a": 1}, "a", 0);`, "g", "int"},
+		{`let p = pad_left("a", 3);`, "p", "string"},
+		{"let t = format_time(0);", "t", "string"},
+		{`let m = merge({"a": 1}, {"b": "x"});`, "m", "{any: any}"},
+		{`let i = items({"a": 1});`, "i", "[[any]]"},
+		{`let z = zip([1], ["a"]);`, "z", "[[any]]"},
+		{"let f = floor;", "f", "fn(int, int?) -> int"},
+	} {
+		types, errors := Infer(parse(t, tt.input))
+		for _, err := range errors {
+			t.Errorf("%s: unexpected error %s", tt.input, err)
+		}
+		if got := types[tt.name]; got == nil || got.String() != tt.expected {
+			t.Errorf("%s: wrong type of %s. want=%q, got=%v", tt.input, tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected []string
+	}{
+		{`"a" - 1`, []string{"1:1: type mismatch: string - int"}},
+		{`"a" - "b"`, []string{"1:1: unknown operation: string - string"}},
+		{"true + false", []string{"1:1: unknown operation: bool + bool"}},
+		{"1 + true", []string{"1:1: type mismatch: int + bool"}},
+		{`-"a"`, []string{"1:1: unknown operation: -string"}},
+		{"let f = fn(x) { x - 1 };\nf(\"a\")",
+			[]string{"2:3: type mismatch: expected int, got string"}},
+		{"let f = fn(x) { x + x; let b: bool = x; b };",
+			[]string{"1:17: unknown operation: bool + bool"}},
+		{"let f = fn(x) { x };\nf(1, 2)", []string{"2:1: wrong number of arguments, expected 1, got 2"}},
+		{"let x = 1; x(2)", []string{"1:12: not a function: int"}},
+		{"len([1], [2])", []string{"1:1: wrong number of arguments, expected 1, got 2"}},
+		{`upper(1)`, []string{"1:7: type mismatch: expected string, got int"}},
+		{"map([1, 2], fn(s) { upper(s) })",
+			[]string{"1:13: type mismatch: expected fn(int) -> a, got fn(string) -> string"}},
+		{"let x: string = 1;", []string{"1:17: type mismatch: expected string, got int"}},
+		{"let x: num = 1;", []string{"1:8: unknown type num"}},
+		{"let f = fn(a: int) { a }; f(\"s\")",
+			[]string{"1:29: type mismatch: expected int, got string"}},
+		{"let f = fn() -> string { 1 };", []string{"1:26: type mismatch: expected string, got int"}},
+		{"let f = fn(n) { if (n) { return 1; }; \"many\" };",
+			[]string{"1:39: type mismatch: expected int, got string"}},
+		{"let xs = [1, 2]; xs[\"a\"]", []string{"1:21: type mismatch: expected int, got string"}},
+		{"let s = \"abc\"; s[0]", []string{"1:16: invalid index operator: string"}},
+		{"let h = {[1]: 2};", []string{"1:10: invalid as hash key: [int]"}},
+		{"let f = fn(x) { x(x) };", []string{"1:17: type mismatch: expected a, got fn(a) -> b"}},
+		{"let f = fn() { g(1) + \"a\" }; let g = fn(x) { x * 2 };",
+			[]string{"1:34: type mismatch: g is used as fn(int) -> string, but is fn(int) -> int"}},
+		{"let xs = []; let ys = append(xs, 1); append(xs, \"a\")",
+			[]string{"1:49: type mismatch: expected int, got string"}},
+		{"let id = fn(x) { x }; id(1) + id(2); id(\"a\") + id(\"b\")", nil},
+		{"if (true) { 1 } else { \"a\" }; 2", nil},
+		{"let f = fn(x) { if (x) { println(x) } }; f(1); f(\"a\")", nil},
+		{"let x = 1; let x = \"a\"; x + \"b\"", nil},
+		{"undefined + 1; json_parse(\"1\") + 1", nil},
+		{"let m = macro(a) { quote(unquote(a) - 1) }; m(\"a\" - 1); quote(\"a\" - 1)", nil},
+		{"let mod = import \"m\"; mod[\"f\"](1)", nil},
+		{"let xs = [1, \"a\"]; xs[0] + 1; xs[1] + \"b\"", nil},
+		{"let h = {\"n\": 1, \"s\": \"a\"}; h[\"n\"] + 1", nil},
+		{"let twice = fn(f, x) { f(f(x)) }; twice(fn(n) { n + 1 }, 1); twice(upper, \"a\")", nil},
+		{"each([1, 2], println)", nil},
+		{"range(1, 2, 3, 4)", []string{"1:1: wrong number of arguments, expected 1 to 3, got 4"}},
+		{`pad_left("a")`, []string{"1:1: wrong number of arguments, expected 2 or 3, got 1"}},
+		{`regex_match(1, "a")`, []string{"1:13: type mismatch: expected string, got int"}},
+		{`get({"a": 1}, 1)`, []string{"1:15: type mismatch: expected string, got int"}},
+		{"items([1])", []string{"1:7: type mismatch: expected {a: b}, got [int]"}},
+		{`json_stringify({"a": [1]}, 2); json_parse("1") + 1`, nil},
+		{"reduce([1, 2], floor, 10)", nil},
+		{"fn(x) { if (x > 0) { \"pos\" } else { 0 } }(1)", nil},
+		{"let f = fn(x) { if (x > 0) { \"pos\" } else { 0 } }; let v = f(1); let n: int = v; let s: string = v;", nil},
+		{"let f = fn(x) { if (x) { return [1, \"a\"][0]; }; 1 }; let v = f(true); let s: string = v;", nil},
+		{"fn() { let a = 1; return a; let b = 2; }() + 1", nil},
+		{"let f = fn(x) { x }; let g = f; g(1) + 1; g(\"a\") + \"b\"", nil},
+	} {
+		errors := Check(parse(t, tt.input))
+
+		if len(errors) != len(tt.expected) {
+			t.Errorf("%s: wrong errors. want=%q, got=%q", tt.input, tt.expected, errors)
+			continue
+		}
+		for i, err := range errors {
+			if err.Error() != tt.expected[i] {
+				t.Errorf("%s: wrong error. want=%q, got=%q", tt.input, tt.expected[i], err)
+			}
+		}
+	}
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("could not parse %q: %s", input, p.Errors())
+	}
+	return program
+}
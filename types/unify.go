@@ -0,0 +1,121 @@
+package types
+
+// unifier makes types equal by finding out what their variables stand for.
+// It keeps a trail of the changes made to variables, so that a failed
+// attempt leaves them as they were.
+type unifier struct {
+	trail []change
+}
+
+// change records the state of a variable before it was changed.
+type change struct {
+	v     *Var
+	bound Type
+	level int
+}
+
+// unify makes a and b the same type if they can be, and reports whether
+// they could. Any is compatible with every type, without telling anything
+// about the variables compared to it.
+func (u *unifier) unify(a, b Type) bool {
+	ok := u.unifyTypes(a, b)
+	if !ok {
+		for i := len(u.trail) - 1; i >= 0; i-- {
+			c := u.trail[i]
+			c.v.bound, c.v.level = c.bound, c.level
+		}
+	}
+	u.trail = u.trail[:0]
+
+	return ok
+}
+
+func (u *unifier) unifyTypes(a, b Type) bool {
+	a, b = resolve(a), resolve(b)
+	if a == b || a == Any || b == Any {
+		return true
+	}
+	if v, ok := a.(*Var); ok {
+		return u.bind(v, b)
+	}
+	if v, ok := b.(*Var); ok {
+		return u.bind(v, a)
+	}
+
+	switch a := a.(type) {
+	case *Array:
+		b, ok := b.(*Array)
+		return ok && u.unifyTypes(a.Element, b.Element)
+	case *Hash:
+		b, ok := b.(*Hash)
+		return ok && u.unifyTypes(a.Key, b.Key) && u.unifyTypes(a.Value, b.Value)
+	case *Function:
+		b, ok := b.(*Function)
+		if !ok {
+			return false
+		}
+		if !a.Variadic && !b.Variadic {
+			if !arityOverlaps(a, b) {
+				return false
+			}
+			for i := 0; i < len(a.Params) && i < len(b.Params); i++ {
+				if !u.unifyTypes(a.Params[i], b.Params[i]) {
+					return false
+				}
+			}
+		}
+		return u.unifyTypes(a.Return, b.Return)
+	default:
+		// Basic types are only equal to themselves.
+		return false
+	}
+}
+
+// arityOverlaps reports whether a and b can be called with the same number
+// of arguments.
+func arityOverlaps(a, b *Function) bool {
+	return len(a.Params)-a.Optional <= len(b.Params) && len(b.Params)-b.Optional <= len(a.Params)
+}
+
+// bind makes v stand for t, unless t contains v, which would make an
+// infinite type.
+func (u *unifier) bind(v *Var, t Type) bool {
+	if !u.adjust(t, v) {
+		return false
+	}
+	u.set(v, t, v.level)
+	return true
+}
+
+// adjust lowers the level of the variables of t to that of v, since they
+// are now known wherever v is, and reports whether v is not among them.
+func (u *unifier) adjust(t Type, v *Var) bool {
+	switch t := resolve(t).(type) {
+	case *Var:
+		if t == v {
+			return false
+		}
+		if t.level > v.level {
+			u.set(t, nil, v.level)
+		}
+		return true
+	case *Array:
+		return u.adjust(t.Element, v)
+	case *Hash:
+		return u.adjust(t.Key, v) && u.adjust(t.Value, v)
+	case *Function:
+		for _, p := range t.Params {
+			if !u.adjust(p, v) {
+				return false
+			}
+		}
+		return u.adjust(t.Return, v)
+	default:
+		return true
+	}
+}
+
+func (u *unifier) set(v *Var, bound Type, level int) {
+	u.trail = append(u.trail, change{v: v, bound: v.bound, level: v.level})
+	v.bound, v.level = bound, level
+}